@@ -3,38 +3,117 @@ package adminapi
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/cynkin/rlaas/limiter"
 	"github.com/cynkin/rlaas/store"
 )
 
 type AdminServer struct {
 	db 			*pgxpool.Pool
 	ruleStore   *store.RuleStore
+	registry    *limiter.Registry
+	resetter    *store.CounterResetter
 }
 
-func NewAdminServer(db *pgxpool.Pool, ruleStore *store.RuleStore) *AdminServer {
+func NewAdminServer(db *pgxpool.Pool, ruleStore *store.RuleStore, registry *limiter.Registry, resetter *store.CounterResetter) *AdminServer {
 	return &AdminServer{
 		db: db,
 		ruleStore: ruleStore,
+		registry: registry,
+		resetter: resetter,
 	}
 }
 
 type CreateRuleRequest struct {
-	RuleID 		string 	`json:"rule_id"`
-	Algorithm 	string 	`json:"algorithm"`
-	Limit 		int 	`json:"limit"`
-	WindowSecs	int 	`json:"window_secs"`
+	RuleID 		string 	 `json:"rule_id"`
+	ClientID	string	 `json:"client_id"`   // optional, makes this an override of rule_id for one client
+	Algorithm 	string 	 `json:"algorithm"`
+	Limit 		int 	 `json:"limit"`
+	WindowSecs	int 	 `json:"window_secs"`
+	Capacity	*int	 `json:"capacity"`    // bucket and gcra algorithms only, defaults to limit (1 for leaky_bucket, where it is the burst)
+	RefillRate	*float64 `json:"refill_rate"` // bucket and gcra algorithms only, defaults to limit / window_secs
+	Period		string	 `json:"period"`      // fixed_window only: "day", "week" or "month", replaces window_secs
+	Timezone	string	 `json:"timezone"`    // IANA name the period follows, defaults to UTC
+	FailureMode	string	 `json:"failure_mode"` // "open", "closed" or "local-fallback", defaults to the server's FAILURE_MODE
+	Match		map[string]string `json:"match"`  // descriptors a check must carry, "*" for any value, "" for absent
+	KeyBy		[]string `json:"key_by"`          // descriptors to keep counters per, defaults to the client
 }
 
 type UpdateRuleRequest struct {
-	Limit 		*int 	`json:"limit"`
-	WindowSecs	*int 	`json:"window_secs"`
-    Enabled     *bool   `json:"enabled"`
+	Algorithm 	*string	 `json:"algorithm"`
+	Limit 		*int 	 `json:"limit"`
+	WindowSecs	*int 	 `json:"window_secs"`
+	Capacity	*int	 `json:"capacity"`
+	RefillRate	*float64 `json:"refill_rate"`
+	Period		*string	 `json:"period"`   // "" switches back to window_secs
+	Timezone	*string	 `json:"timezone"`
+	FailureMode	*string	 `json:"failure_mode"` // "" goes back to the server default
+	Match		*map[string]string `json:"match"` // {} matches every check again
+	KeyBy		*[]string `json:"key_by"`         // [] goes back to per client
+    Enabled     *bool    `json:"enabled"`
+}
+
+// validateCalendar checks a calendar period and timezone pair. Either may be empty.
+func validateCalendar(period, timezone string) error {
+	if period != "" && !store.IsCalendarPeriod(period) {
+		return fmt.Errorf("period must be one of %v", store.CalendarPeriods)
+	}
+	if _, err := store.LoadTimezone(timezone); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateAlgorithm rejects algorithm names the registry doesn't know
+func (a *AdminServer) validateAlgorithm(name string) error {
+	if _, ok := a.registry.Get(name); !ok {
+		return fmt.Errorf("unknown algorithm %q, see GET /algorithms", name)
+	}
+	return nil
+}
+
+// validateFailureMode checks a rule's failure mode, empty meaning the server default
+func validateFailureMode(mode string) error {
+	if mode != "" && !store.IsFailureMode(mode) {
+		return fmt.Errorf("failure_mode must be one of %v", store.FailureModes)
+	}
+	return nil
+}
+
+// validateDescriptors checks a rule's match and key_by, either may be empty
+func validateDescriptors(match map[string]string, keyBy []string) error {
+	for key := range match {
+		if key == "" {
+			return fmt.Errorf("match keys must not be empty")
+		}
+	}
+	for i, key := range keyBy {
+		if key == "" {
+			return fmt.Errorf("key_by entries must not be empty")
+		}
+		if slices.Contains(keyBy[:i], key) {
+			return fmt.Errorf("key_by lists %q twice", key)
+		}
+	}
+	return nil
+}
+
+// ruleStatus is the body returned after changing a rule, client_id only for overrides
+func ruleStatus(status, ruleID, clientID string) map[string]string {
+	body := map[string]string{"status": status, "rule_id": ruleID}
+	if clientID != "" {
+		body["client_id"] = clientID
+	}
+	return body
 }
 
 func cors(next http.Handler) http.Handler {
@@ -59,6 +138,8 @@ func (a *AdminServer) Start(port string) {
 	mux.HandleFunc("POST /rules", a.createRule)
 	mux.HandleFunc("PATCH /rules/{rule_id}", a.updateRule)
 	mux.HandleFunc("DELETE /rules/{rule_id}", a.deleteRule)
+	mux.HandleFunc("POST /rules/{rule_id}/reset", a.resetRule)
+	mux.HandleFunc("GET /algorithms", a.listAlgorithms)
 
 	fmt.Printf("✓ Admin API listening on port %s\n", port)
 	http.ListenAndServe(":"+port, cors(mux))
@@ -66,7 +147,10 @@ func (a *AdminServer) Start(port string) {
 
 func (a *AdminServer) listRules(w http.ResponseWriter, r *http.Request) {
 	rows, err := a.db.Query(r.Context(), `
-		SELECT rule_id, COALESCE(client_id, ''), algorithm, "limit", window_secs, enabled, created_at
+		SELECT rule_id, COALESCE(client_id, ''), algorithm, "limit", window_secs,
+			capacity, refill_rate, COALESCE(period, ''), COALESCE(timezone, ''),
+			COALESCE(failure_mode, ''), COALESCE(match_descriptors, '{}'), COALESCE(key_by, '{}'),
+			enabled, created_at
 		FROM rules ORDER BY created_at
 	`)
 	if err != nil {
@@ -81,6 +165,13 @@ func (a *AdminServer) listRules(w http.ResponseWriter, r *http.Request) {
 		Algorithm  string    `json:"algorithm"`
 		Limit      int       `json:"limit"`
 		WindowSecs int       `json:"window_secs"`
+		Capacity   *int      `json:"capacity,omitempty"`
+		RefillRate *float64  `json:"refill_rate,omitempty"`
+		Period     string    `json:"period,omitempty"`
+		Timezone   string    `json:"timezone,omitempty"`
+		FailureMode string   `json:"failure_mode,omitempty"`
+		Match      map[string]string `json:"match,omitempty"`
+		KeyBy      []string  `json:"key_by,omitempty"`
 		Enabled    bool      `json:"enabled"`
 		CreatedAt  time.Time `json:"created_at"`
 	}
@@ -94,6 +185,13 @@ func (a *AdminServer) listRules(w http.ResponseWriter, r *http.Request) {
 			&rule.Algorithm,
 			&rule.Limit, 
 			&rule.WindowSecs, 
+			&rule.Capacity,
+			&rule.RefillRate,
+			&rule.Period,
+			&rule.Timezone,
+			&rule.FailureMode,
+			&rule.Match,
+			&rule.KeyBy,
 			&rule.Enabled, 
 			&rule.CreatedAt,
 		)
@@ -108,6 +206,11 @@ func (a *AdminServer) listRules(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(rules)
 }
 
+func (a *AdminServer) listAlgorithms(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a.registry.List())
+}
+
 func (a *AdminServer) createRule(w http.ResponseWriter, r *http.Request) {
 	var req CreateRuleRequest
 
@@ -116,8 +219,13 @@ func (a *AdminServer) createRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if req.RuleID == "" || req.Limit == 0 || req.WindowSecs == 0 {
-		http.Error(w, "rule_id, limit, and window_secs are required", http.StatusBadRequest)
+	// Calendar rules get their window from the period instead
+	if req.RuleID == "" || req.Limit == 0 || (req.WindowSecs == 0 && req.Period == "") {
+		http.Error(w, "rule_id, limit, and window_secs (or period) are required", http.StatusBadRequest)
+		return
+	}
+	if req.Limit < 0 || req.WindowSecs < 0 {
+		http.Error(w, "limit and window_secs must be positive", http.StatusBadRequest)
 		return
 	}
 
@@ -125,10 +233,42 @@ func (a *AdminServer) createRule(w http.ResponseWriter, r *http.Request) {
 		req.Algorithm = "fixed_window"
 	}
 
+	if err := a.validateAlgorithm(req.Algorithm); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := validateCalendar(req.Period, req.Timezone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateFailureMode(req.FailureMode); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := validateDescriptors(req.Match, req.KeyBy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Match == nil {
+		req.Match = map[string]string{}
+	}
+	if req.Period != "" && req.Algorithm != "fixed_window" {
+		http.Error(w, "period is only supported by fixed_window", http.StatusBadRequest)
+		return
+	}
+
+	if (req.Capacity != nil && *req.Capacity <= 0) || (req.RefillRate != nil && *req.RefillRate <= 0) {
+		http.Error(w, "capacity and refill_rate must be positive", http.StatusBadRequest)
+		return
+	}
+
 	_, err := a.db.Exec(r.Context(), `
-		INSERT INTO rules (rule_id, algorithm, "limit", window_secs)
-		VALUES ($1, $2, $3, $4)
-	`, req.RuleID, req.Algorithm, req.Limit, req.WindowSecs)
+		INSERT INTO rules (rule_id, client_id, algorithm, "limit", window_secs, capacity, refill_rate, period, timezone, failure_mode,
+			match_descriptors, key_by)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11, $12)
+	`, req.RuleID, req.ClientID, req.Algorithm, req.Limit, req.WindowSecs, req.Capacity, req.RefillRate, req.Period, req.Timezone, req.FailureMode,
+		req.Match, req.KeyBy)
 
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to create rule: %v", err), http.StatusInternalServerError)
@@ -138,11 +278,12 @@ func (a *AdminServer) createRule(w http.ResponseWriter, r *http.Request) {
 	a.ruleStore.InvalidateCache()
 
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{"status": "created", "rule_id": req.RuleID})
+	json.NewEncoder(w).Encode(ruleStatus("created", req.RuleID, req.ClientID))
 }
 
 func (a *AdminServer) updateRule(w http.ResponseWriter, r *http.Request) {
 	ruleID := r.PathValue("rule_id")
+	clientID := r.URL.Query().Get("client_id") // which client's override, empty for the generic rule
 
 	var req UpdateRuleRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -150,14 +291,118 @@ func (a *AdminServer) updateRule(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err := a.db.Exec(r.Context(), `
+	if req.Algorithm != nil {
+		if err := a.validateAlgorithm(*req.Algorithm); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	if (req.Limit != nil && *req.Limit <= 0) || (req.WindowSecs != nil && *req.WindowSecs <= 0) {
+		http.Error(w, "limit and window_secs must be positive", http.StatusBadRequest)
+		return
+	}
+	if (req.Capacity != nil && *req.Capacity <= 0) || (req.RefillRate != nil && *req.RefillRate <= 0) {
+		http.Error(w, "capacity and refill_rate must be positive", http.StatusBadRequest)
+		return
+	}
+
+	var period, timezone string
+	if req.Period != nil {
+		period = *req.Period
+	}
+	if req.Timezone != nil {
+		timezone = *req.Timezone
+	}
+	if err := validateCalendar(period, timezone); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.FailureMode != nil {
+		if err := validateFailureMode(*req.FailureMode); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var match map[string]string
+	var keyBy []string
+	if req.Match != nil {
+		match = *req.Match
+	}
+	if req.KeyBy != nil {
+		keyBy = *req.KeyBy
+	}
+	if err := validateDescriptors(match, keyBy); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tx, err := a.db.Begin(r.Context())
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to update rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+	defer tx.Rollback(r.Context())
+
+	// The period/algorithm check from createRule, against the rule as it will
+	// be after the patch. Locked so a concurrent patch can't slip past it.
+	var algorithm, rulePeriod string
+	var windowSecs int
+	err = tx.QueryRow(r.Context(), `
+		SELECT algorithm, window_secs, COALESCE(period, '')
+		FROM rules
+		WHERE rule_id = $1 AND client_id IS NOT DISTINCT FROM NULLIF($2, '')
+		FOR UPDATE
+	`, ruleID, clientID).Scan(&algorithm, &windowSecs, &rulePeriod)
+	if errors.Is(err, pgx.ErrNoRows) {
+		http.Error(w, fmt.Sprintf("rule %q not found", ruleID), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to update rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if req.Algorithm != nil {
+		algorithm = *req.Algorithm
+	}
+	if req.WindowSecs != nil {
+		windowSecs = *req.WindowSecs
+	}
+	if req.Period != nil {
+		rulePeriod = period
+	}
+	if rulePeriod != "" && algorithm != "fixed_window" {
+		http.Error(w, "period is only supported by fixed_window", http.StatusBadRequest)
+		return
+	}
+	if rulePeriod == "" && windowSecs <= 0 {
+		http.Error(w, "window_secs is required without a period", http.StatusBadRequest)
+		return
+	}
+
+	// Empty period/timezone/failure_mode clears them, missing leaves them alone
+	_, err = tx.Exec(r.Context(), `
 		UPDATE rules SET
-			"limit"     = COALESCE($1, "limit"),
-			window_secs = COALESCE($2, window_secs),
-			enabled     = COALESCE($3, enabled),
+			algorithm   = COALESCE($1, algorithm),
+			"limit"     = COALESCE($2, "limit"),
+			window_secs = COALESCE($3, window_secs),
+			capacity    = COALESCE($4, capacity),
+			refill_rate = COALESCE($5, refill_rate),
+			period      = CASE WHEN $6::text IS NULL THEN period ELSE NULLIF($6, '') END,
+			timezone    = CASE WHEN $7::text IS NULL THEN timezone ELSE NULLIF($7, '') END,
+			failure_mode = CASE WHEN $8::text IS NULL THEN failure_mode ELSE NULLIF($8, '') END,
+			enabled     = COALESCE($9, enabled),
+			match_descriptors = COALESCE($12, match_descriptors),
+			key_by      = COALESCE($13, key_by),
 			updated_at  = NOW()
-		WHERE rule_id = $4
-	`, req.Limit, req.WindowSecs, req.Enabled, ruleID)
+		WHERE rule_id = $10 AND client_id IS NOT DISTINCT FROM NULLIF($11, '')
+	`, req.Algorithm, req.Limit, req.WindowSecs, req.Capacity, req.RefillRate, req.Period, req.Timezone, req.FailureMode, req.Enabled, ruleID, clientID,
+		req.Match, req.KeyBy)
+	if err == nil {
+		err = tx.Commit(r.Context())
+	}
 
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to update rule: %v", err), http.StatusInternalServerError)
@@ -167,16 +412,17 @@ func (a *AdminServer) updateRule(w http.ResponseWriter, r *http.Request) {
 	a.ruleStore.InvalidateCache()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "updated", "rule_id": ruleID})
+	json.NewEncoder(w).Encode(ruleStatus("updated", ruleID, clientID))
 }
 
 func (a *AdminServer) deleteRule(w http.ResponseWriter, r *http.Request) {
 	ruleID := r.PathValue("rule_id")
+	clientID := r.URL.Query().Get("client_id")
 
 	_, err := a.db.Exec(r.Context(), `
 		UPDATE rules SET enabled = false, updated_at = NOW()
-		WHERE rule_id = $1
-	`, ruleID)
+		WHERE rule_id = $1 AND client_id IS NOT DISTINCT FROM NULLIF($2, '')
+	`, ruleID, clientID)
 
 	if err != nil {
 		http.Error(w, fmt.Sprintf("failed to disable rule: %v", err), http.StatusInternalServerError)
@@ -186,7 +432,50 @@ func (a *AdminServer) deleteRule(w http.ResponseWriter, r *http.Request) {
 	a.ruleStore.InvalidateCache()
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"status": "disabled", "rule_id": ruleID})
+	json.NewEncoder(w).Encode(ruleStatus("disabled", ruleID, clientID))
+}
+
+// resetRule clears a rule's counters: one client's with ?client_id=, every
+// client's without
+func (a *AdminServer) resetRule(w http.ResponseWriter, r *http.Request) {
+	ruleID := r.PathValue("rule_id")
+	clientID := r.URL.Query().Get("client_id")
+
+	// No falling back to "default" here, a typo would wipe its counters
+	rule, ok, err := a.ruleStore.FindRule(r.Context(), ruleID, clientID)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("rule lookup failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.Error(w, fmt.Sprintf("no rule matches %q", ruleID), http.StatusNotFound)
+		return
+	}
+
+	body := ruleStatus("reset", rule.RuleID, clientID)
+	if clientID == "" {
+		var cleared int
+		cleared, err = a.resetter.ResetAll(r.Context(), rule.CounterID(ruleID))
+		body["keys_cleared"] = strconv.Itoa(cleared)
+	} else if slices.ContainsFunc(rule.KeyBy, func(key string) bool { return key != "client_id" }) {
+		// A match alone still counts per client, only key_by takes the client's place
+		http.Error(w, "rule keys counters on descriptors, reset every client or use the ResetLimit RPC", http.StatusBadRequest)
+		return
+	} else {
+		err = a.resetter.Reset(r.Context(), rule, rule.CounterKey(ruleID, store.Descriptors{"client_id": clientID}))
+	}
+
+	switch {
+	case errors.Is(err, store.ErrNoCounters), errors.Is(err, store.ErrResetAllUnsupported):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		http.Error(w, fmt.Sprintf("failed to reset rule: %v", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
 }
 
 func (a *AdminServer) getMetrics(w http.ResponseWriter, r *http.Request) {
@@ -262,6 +551,8 @@ func (a *AdminServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	mux.HandleFunc("POST /rules", a.createRule)
 	mux.HandleFunc("PATCH /rules/{rule_id}", a.updateRule)
 	mux.HandleFunc("DELETE /rules/{rule_id}", a.deleteRule)
+	mux.HandleFunc("POST /rules/{rule_id}/reset", a.resetRule)
+	mux.HandleFunc("GET /algorithms", a.listAlgorithms)
 	mux.ServeHTTP(w, r)
 }
 
@@ -0,0 +1,81 @@
+package adminapi
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cynkin/rlaas/store"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestResetRule(t *testing.T) {
+	rules := []store.Rule{
+		{RuleID: "login", Algorithm: "fixed_window", Limit: 5, WindowSecs: 60},
+		// Counted per client like login, the match only picks which checks count
+		{RuleID: "orders", Algorithm: "fixed_window", Limit: 5, WindowSecs: 60, Match: map[string]string{"method": "POST"}},
+		{RuleID: "search", Algorithm: "fixed_window", Limit: 5, WindowSecs: 60, KeyBy: []string{"ip"}},
+		{RuleID: "upload", Algorithm: "fixed_window", Limit: 5, WindowSecs: 60, KeyBy: []string{"client_id"}},
+	}
+
+	tests := []struct {
+		name     string
+		ruleID   string
+		clientID string
+		d        store.Descriptors // what the counted check carried
+		want     int
+		cleared  bool // whether the counted check's counter went
+	}{
+		{name: "one client", ruleID: "login", clientID: "acme", want: http.StatusOK, cleared: true},
+		{name: "one client of a match-only rule", ruleID: "orders", clientID: "acme", d: store.Descriptors{"method": "POST"}, want: http.StatusOK, cleared: true},
+		{name: "one client of a key_by client_id rule", ruleID: "upload", clientID: "acme", want: http.StatusOK, cleared: true},
+		{name: "one client of a key_by rule", ruleID: "search", clientID: "acme", d: store.Descriptors{"ip": "10.0.0.1"}, want: http.StatusBadRequest},
+		{name: "every client of a key_by rule", ruleID: "search", d: store.Descriptors{"ip": "10.0.0.1"}, want: http.StatusOK, cleared: true},
+		{name: "unknown rule", ruleID: "logn", clientID: "acme", want: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			registry := store.NewRedisRegistry(client)
+			ruleStore := store.NewStaticRuleStore(rules...)
+			a := NewAdminServer(nil, ruleStore, registry, store.NewCounterResetter(client, registry))
+			ctx := context.Background()
+
+			// Count one check for acme under the rule, if there is one
+			rule, ok, _ := ruleStore.FindRule(ctx, tt.ruleID, "acme")
+			if ok {
+				cfg, err := rule.Config()
+				if err != nil {
+					t.Fatal(err)
+				}
+				l, err := registry.New(rule.Algorithm, cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				d := store.Descriptors{"client_id": "acme"}
+				for k, v := range tt.d {
+					d[k] = v
+				}
+				if _, err := l.Allow(ctx, rule.CounterKey(tt.ruleID, d), 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/rules/"+tt.ruleID+"/reset?client_id="+tt.clientID, nil)
+			req.SetPathValue("rule_id", tt.ruleID)
+			rec := httptest.NewRecorder()
+			a.resetRule(rec, req)
+
+			if rec.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.want, rec.Body)
+			}
+			if cleared := ok && len(mr.Keys()) == 0; cleared != tt.cleared {
+				t.Errorf("counter cleared = %v, want %v (keys left %v)", cleared, tt.cleared, mr.Keys())
+			}
+		})
+	}
+}
@@ -45,7 +45,11 @@ const Badge = ({ enabled }: { enabled: boolean }) => (
 const ALG_COLORS: Record<string, string> = {
   fixed_window:   'bg-blue-500/10 text-blue-400',
   sliding_window: 'bg-violet-500/10 text-violet-400',
+  sliding_window_counter: 'bg-indigo-500/10 text-indigo-400',
   token_bucket:   'bg-amber-500/10 text-amber-400',
+  leaky_bucket:   'bg-cyan-500/10 text-cyan-400',
+  gcra:           'bg-pink-500/10 text-pink-400',
+  in_flight:      'bg-orange-500/10 text-orange-400',
 }
 const AlgBadge = ({ alg }: { alg: string }) => (
   <span className={`rounded-full px-2.5 py-0.5 text-xs font-medium ${ALG_COLORS[alg] ?? 'bg-zinc-800 text-zinc-400'}`}>
@@ -60,7 +64,7 @@ type ModalProps = {
   onSave: () => void
 }
 
-const ALGORITHMS = ['fixed_window', 'sliding_window', 'token_bucket']
+const ALGORITHMS = ['fixed_window', 'sliding_window', 'sliding_window_counter', 'token_bucket', 'leaky_bucket', 'gcra', 'in_flight']
 
 function RuleModal({ rule, onClose, onSave }: ModalProps) {
   const isEdit = !!rule
@@ -0,0 +1,122 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/metrics"
+	pb "github.com/cynkin/rlaas/proto"
+	"github.com/cynkin/rlaas/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Bigger batches hold one pipeline and one response for too long, split them
+const maxBatchItems = 1000
+
+// batchItem is a resolved BatchCheckLimit item
+type batchItem struct {
+	rule  store.Rule
+	check int // index into the batch's checks, -1 when the rule didn't match and nothing is counted
+}
+
+func (s *RateLimiterServer) BatchCheckLimit(ctx context.Context, req *pb.BatchCheckLimitRequest) (*pb.BatchCheckLimitResponse, error) {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	requestStart := time.Now()
+
+	if len(req.Items) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one item is required")
+	}
+	if len(req.Items) > maxBatchItems {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d items per batch, got %d", maxBatchItems, len(req.Items))
+	}
+
+	// Resolve every item up front so the checks go out in one pipeline
+	items := make([]batchItem, len(req.Items))
+	checks := make([]store.BatchCheck, 0, len(req.Items))
+	for i, item := range req.Items {
+		cost := int(item.Cost)
+		if cost < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "item %d: cost must not be negative, got %d", i, item.Cost)
+		}
+		if cost == 0 {
+			cost = 1
+		}
+
+		descriptors, err := descriptorsOf(item.ClientId, item.Descriptors)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		rule, err := s.ruleStore.GetRule(ctx, item.RuleId, item.ClientId)
+		if err != nil {
+			return nil, fmt.Errorf("rule lookup failed: %w", err)
+		}
+
+		items[i] = batchItem{rule: rule, check: -1}
+		if !rule.Matches(descriptors) {
+			continue
+		}
+
+		l, err := s.limiterFor(rule)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+		if err := s.checkCost(rule, cost); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+
+		items[i].check = len(checks)
+		checks = append(checks, store.BatchCheck{
+			Limiter:   l,
+			ClientKey: rule.CounterKey(item.RuleId, descriptors),
+			Cost:      cost,
+		})
+	}
+
+	redisStart := time.Now()
+	results, errs := s.allowBatch(ctx, checks)
+	metrics.RedisDuration.With(prometheus.Labels{
+		"operation": "batch",
+	}).Observe(time.Since(redisStart).Seconds())
+
+	resp := &pb.BatchCheckLimitResponse{Results: make([]*pb.CheckLimitResponse, len(items))}
+	logs := make([]requestLog, 0, len(checks))
+	for i, item := range items {
+		rule := item.rule
+		if item.check < 0 {
+			resp.Results[i] = &pb.CheckLimitResponse{Allowed: true, Algorithm: rule.Algorithm}
+			continue
+		}
+
+		// Backend trouble: each item's rule decides by its own failure mode
+		check, res, err := checks[item.check], results[item.check], errs[item.check]
+		degraded := err != nil
+		if degraded {
+			res, err = s.degradedDecision(ctx, rule, check.ClientKey, check.Cost, err)
+			if err != nil {
+				return nil, err
+			}
+		}
+		recordDecision(rule, res.Allowed, requestStart)
+		logs = append(logs, requestLog{clientID: req.Items[i].ClientId, ruleID: req.Items[i].RuleId, allowed: res.Allowed})
+
+		resp.Results[i] = &pb.CheckLimitResponse{
+			Allowed:       res.Allowed,
+			Remaining:     int32(res.Remaining),
+			RetryAfterMs:  millisCeil(res.RetryAfter),
+			Algorithm:     rule.Algorithm,
+			ResetAtMs:     res.ResetAt.UnixMilli(),
+			Degraded:      degraded,
+			MatchedRuleId: rule.RuleID,
+		}
+	}
+
+	s.logRequests(logs...)
+
+	return resp, nil
+}
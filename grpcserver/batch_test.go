@@ -0,0 +1,92 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+
+	pb "github.com/cynkin/rlaas/proto"
+	"github.com/cynkin/rlaas/store"
+)
+
+func TestBatchCheckLimit(t *testing.T) {
+	posts := store.Rule{RuleID: "posts", Algorithm: "token_bucket", Limit: 1, WindowSecs: 3600, Match: map[string]string{"method": "POST"}}
+	s, _ := newTestServer(t, testBucket, testGCRA, posts)
+	ctx := context.Background()
+
+	item := func(ruleID, clientID string, cost int32, kv ...string) *pb.CheckLimitRequest {
+		req := &pb.CheckLimitRequest{RuleId: ruleID, ClientId: clientID, Cost: cost}
+		for i := 0; i < len(kv); i += 2 {
+			req.Descriptors = append(req.Descriptors, &pb.Descriptor{Key: kv[i], Value: kv[i+1]})
+		}
+		return req
+	}
+
+	type want struct {
+		allowed   bool
+		remaining int32
+		matched   string
+	}
+	tests := []struct {
+		name  string
+		flush bool // SCRIPT FLUSH first, so every pipelined script comes back NOSCRIPT
+		items []*pb.CheckLimitRequest
+		want  []want
+	}{
+		{
+			name: "mixed",
+			items: []*pb.CheckLimitRequest{
+				item("login", "acme", 3),
+				item("login", "acme", 1), // the first item took all of it
+				item("search", "acme", 1),
+				item("posts", "acme", 1, "method", "GET"), // rule doesn't match, nothing counted
+				item("posts", "acme", 1, "method", "POST"),
+			},
+			want: []want{
+				{allowed: true, remaining: 0, matched: "login"},
+				{allowed: false, remaining: 0, matched: "login"},
+				{allowed: true, remaining: 1, matched: "search"},
+				{allowed: true},
+				{allowed: true, remaining: 0, matched: "posts"},
+			},
+		},
+		{
+			name:  "scripts not cached",
+			flush: true,
+			items: []*pb.CheckLimitRequest{
+				item("search", "acme", 1),
+				item("search", "acme", 1),
+				item("login", "beta", 2),
+			},
+			// Each check counted exactly once, by Allow sending the script itself
+			want: []want{
+				{allowed: true, remaining: 0, matched: "search"},
+				{allowed: false, remaining: 0, matched: "search"},
+				{allowed: true, remaining: 1, matched: "login"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.flush {
+				if err := s.redisClient.ScriptFlush(ctx).Err(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			resp, err := s.BatchCheckLimit(ctx, &pb.BatchCheckLimitRequest{Items: tt.items})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Results) != len(tt.want) {
+				t.Fatalf("%d results, want %d", len(resp.Results), len(tt.want))
+			}
+			for i, w := range tt.want {
+				got := resp.Results[i]
+				if got.Allowed != w.allowed || got.Remaining != w.remaining || got.MatchedRuleId != w.matched || got.Degraded {
+					t.Errorf("item %d: %v, want %+v", i, got, w)
+				}
+			}
+		})
+	}
+}
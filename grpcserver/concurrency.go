@@ -0,0 +1,118 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/metrics"
+	pb "github.com/cynkin/rlaas/proto"
+	"github.com/cynkin/rlaas/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// inFlightLimiter resolves an "in_flight" rule and builds its limiter. Lease TTL
+// defaults to the rule's window so a crashed caller frees its slot eventually.
+func (s *RateLimiterServer) inFlightLimiter(ctx context.Context, ruleID, clientID string, leaseTTL time.Duration) (store.Rule, *store.AtomicConcurrencyLimiter, error) {
+	rule, err := s.ruleStore.GetRule(ctx, ruleID, clientID)
+	if err != nil {
+		return store.Rule{}, nil, fmt.Errorf("rule lookup failed: %w", err)
+	}
+
+	if rule.Algorithm != "in_flight" {
+		return store.Rule{}, nil, status.Errorf(codes.FailedPrecondition,
+			"rule %q uses %s, not in_flight", rule.RuleID, rule.Algorithm)
+	}
+
+	if s.redisClient == nil {
+		return store.Rule{}, nil, status.Error(codes.Unimplemented, "in_flight rules need the redis backend")
+	}
+
+	if leaseTTL <= 0 {
+		leaseTTL = time.Duration(rule.WindowSecs) * time.Second
+	}
+
+	return rule, store.NewAtomicConcurrency(s.redisClient, rule.Limit, leaseTTL), nil
+}
+
+func (s *RateLimiterServer) AcquireConcurrency(ctx context.Context, req *pb.AcquireConcurrencyRequest) (*pb.AcquireConcurrencyResponse, error) {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	requestStart := time.Now()
+
+	rule, cl, err := s.inFlightLimiter(ctx, req.RuleId, req.ClientId, time.Duration(req.LeaseTtlMs)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
+
+	clientKey, err := clientKeyFor(rule, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	redisStart := time.Now()
+	var acquired bool
+	var lease store.Lease
+	var remaining int
+	var retryAfter time.Duration
+	err = s.viaBackend(ctx, func() (err error) {
+		acquired, lease, remaining, retryAfter, err = cl.Acquire(ctx, clientKey)
+		return err
+	})
+	metrics.RedisDuration.With(prometheus.Labels{
+		"operation": "in_flight_acquire",
+	}).Observe(time.Since(redisStart).Seconds())
+
+	// Slots are kept in the backend, no failure mode can stand in for them
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "concurrency limiter backend error: %v", err)
+	}
+
+	recordDecision(rule, acquired, requestStart)
+	s.logRequests(requestLog{clientID: req.ClientId, ruleID: req.RuleId, allowed: acquired})
+
+	if !acquired {
+		return &pb.AcquireConcurrencyResponse{
+			Acquired:     false,
+			RetryAfterMs: millisCeil(retryAfter),
+		}, nil
+	}
+
+	return &pb.AcquireConcurrencyResponse{
+		Acquired:    true,
+		LeaseId:     lease.ID,
+		Remaining:   int32(remaining),
+		ExpiresAtMs: lease.ExpiresAt.UnixMilli(),
+	}, nil
+}
+
+func (s *RateLimiterServer) ReleaseConcurrency(ctx context.Context, req *pb.ReleaseConcurrencyRequest) (*pb.ReleaseConcurrencyResponse, error) {
+	if req.LeaseId == "" {
+		return nil, status.Error(codes.InvalidArgument, "lease_id is required")
+	}
+
+	rule, cl, err := s.inFlightLimiter(ctx, req.RuleId, req.ClientId, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	clientKey, err := clientKeyFor(rule, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	redisStart := time.Now()
+	released, err := cl.Release(ctx, clientKey, req.LeaseId)
+	metrics.RedisDuration.With(prometheus.Labels{
+		"operation": "in_flight_release",
+	}).Observe(time.Since(redisStart).Seconds())
+
+	if err != nil {
+		return nil, fmt.Errorf("concurrency limiter error: %w", err)
+	}
+
+	return &pb.ReleaseConcurrencyResponse{Released: released}, nil
+}
@@ -0,0 +1,52 @@
+package grpcserver
+
+import (
+	"github.com/cynkin/rlaas/store"
+	pb "github.com/cynkin/rlaas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// descriptorsOf collects a check's descriptors. The client ID goes in under
+// "client_id" unless it is empty, so rules can match anonymous checks too.
+func descriptorsOf(clientID string, descriptors []*pb.Descriptor) (store.Descriptors, error) {
+	d := make(store.Descriptors, len(descriptors)+1)
+	if clientID != "" {
+		d["client_id"] = clientID
+	}
+
+	for _, desc := range descriptors {
+		switch {
+		case desc.Key == "":
+			return nil, status.Error(codes.InvalidArgument, "descriptor keys must not be empty")
+		case desc.Key == "client_id":
+			return nil, status.Error(codes.InvalidArgument, "client_id is set from the request's client_id, not a descriptor")
+		}
+		if _, ok := d[desc.Key]; ok {
+			return nil, status.Errorf(codes.InvalidArgument, "descriptor %q given more than once", desc.Key)
+		}
+		d[desc.Key] = desc.Value
+	}
+	return d, nil
+}
+
+// clientKeyFor is the counter key for RPCs that only carry a client ID. Rules
+// matching or keyed on other descriptors can only be checked with CheckLimit.
+func clientKeyFor(rule store.Rule, requested, clientID string) (string, error) {
+	if rule.UsesDescriptors() {
+		return "", status.Errorf(codes.FailedPrecondition,
+			"rule %q matches on descriptors, use CheckLimit", rule.RuleID)
+	}
+	return rule.CounterKey(requested, store.Descriptors{"client_id": clientID}), nil
+}
+
+// requireKeyBy checks the descriptors name one of the rule's counters, for RPCs
+// that work on a counter directly. The rule's match doesn't matter there.
+func requireKeyBy(rule store.Rule, d store.Descriptors) error {
+	for _, key := range rule.KeyBy {
+		if _, ok := d[key]; !ok {
+			return status.Errorf(codes.InvalidArgument, "rule %q keys counters on %v, descriptor %q is missing", rule.RuleID, rule.KeyBy, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/cynkin/rlaas/metrics"
+	rlspb "github.com/cynkin/rlaas/proto/envoy"
+	"github.com/cynkin/rlaas/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// EnvoyServer answers Envoy's ratelimit filter (envoy.service.ratelimit.v3) from
+// the same rules and counters as CheckLimit, so Envoy can point straight at rlaas.
+type EnvoyServer struct {
+	rlspb.UnimplementedRateLimitServiceServer
+	limits *RateLimiterServer
+}
+
+func NewEnvoyServer(limits *RateLimiterServer) *EnvoyServer {
+	return &EnvoyServer{limits: limits}
+}
+
+// Each Envoy descriptor is one check. A "rule_id" entry picks the rule, the
+// domain otherwise; a "client_id" entry is the client. Every other entry is a
+// descriptor the rule can match or key on, e.g. Envoy's remote_address.
+const envoyRuleEntry = "rule_id"
+
+func (e *EnvoyServer) ShouldRateLimit(ctx context.Context, req *rlspb.RateLimitRequest) (*rlspb.RateLimitResponse, error) {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	if len(req.Descriptors) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one descriptor is required")
+	}
+
+	resp := &rlspb.RateLimitResponse{
+		OverallCode: rlspb.RateLimitResponse_OK,
+		Statuses:    make([]*rlspb.RateLimitResponse_DescriptorStatus, 0, len(req.Descriptors)),
+	}
+
+	// Headers describe the descriptor closest to its limit
+	var tightest *checkOutcome
+	for _, desc := range req.Descriptors {
+		ruleID, descriptors, err := envoyDescriptor(req.Domain, desc)
+		if err != nil {
+			return nil, err
+		}
+
+		// hits_addend of 0 means 1, a descriptor's own addend wins over the request's
+		cost := max(1, int(req.HitsAddend))
+		if desc.HitsAddend != nil {
+			cost = max(1, int(desc.HitsAddend.Value))
+		}
+
+		// Unlike CheckLimit there is no falling back to "default": Envoy sends
+		// every descriptor its route config has, configured here or not, and
+		// they would all share one counter. Like Envoy's own ratelimit service,
+		// descriptors without a rule are OK.
+		clientID := descriptors["client_id"]
+		rule, ok, err := e.limits.ruleStore.FindRule(ctx, ruleID, clientID)
+		if err != nil {
+			return nil, fmt.Errorf("rule lookup failed: %w", err)
+		}
+		out := checkOutcome{rule: rule}
+		if ok {
+			// Like CheckLimit, hits that could never fit are an error rather
+			// than an OVER_LIMIT with a reset that never helps
+			if rule.Matches(descriptors) {
+				if err := e.limits.checkCost(rule, cost); err != nil {
+					return nil, err
+				}
+			}
+			out, err = e.limits.checkRule(ctx, rule, ruleID, clientID, descriptors, cost)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		resp.Statuses = append(resp.Statuses, envoyStatus(out))
+		if !out.matched {
+			continue
+		}
+		if !out.res.Allowed {
+			resp.OverallCode = rlspb.RateLimitResponse_OVER_LIMIT
+		}
+		if tightest == nil || out.res.Remaining < tightest.res.Remaining {
+			tightest = &out
+		}
+	}
+
+	if tightest != nil {
+		resp.ResponseHeadersToAdd = envoyHeaders(*tightest)
+	}
+	return resp, nil
+}
+
+// envoyDescriptor splits an Envoy descriptor into the rule ID and our descriptors
+func envoyDescriptor(domain string, desc *rlspb.RateLimitDescriptor) (string, store.Descriptors, error) {
+	ruleID := domain
+	d := make(store.Descriptors, len(desc.Entries))
+	for _, entry := range desc.Entries {
+		switch entry.Key {
+		case "":
+			return "", nil, status.Error(codes.InvalidArgument, "descriptor entry keys must not be empty")
+		case envoyRuleEntry:
+			ruleID = entry.Value
+			continue
+		}
+		if _, ok := d[entry.Key]; ok {
+			return "", nil, status.Errorf(codes.InvalidArgument, "descriptor entry %q given more than once", entry.Key)
+		}
+		d[entry.Key] = entry.Value
+	}
+
+	// Same as CheckLimit, where an empty client ID isn't a descriptor
+	if d["client_id"] == "" {
+		delete(d, "client_id")
+	}
+	return ruleID, d, nil
+}
+
+// envoyStatus is a check's per-descriptor status. Descriptors no rule covered
+// are OK without a current limit.
+func envoyStatus(out checkOutcome) *rlspb.RateLimitResponse_DescriptorStatus {
+	if !out.matched {
+		return &rlspb.RateLimitResponse_DescriptorStatus{Code: rlspb.RateLimitResponse_OK}
+	}
+
+	code := rlspb.RateLimitResponse_OK
+	if !out.res.Allowed {
+		code = rlspb.RateLimitResponse_OVER_LIMIT
+	}
+	return &rlspb.RateLimitResponse_DescriptorStatus{
+		Code: code,
+		CurrentLimit: &rlspb.RateLimitResponse_RateLimit{
+			Name:            out.rule.RuleID,
+			RequestsPerUnit: uint32(out.limit),
+			Unit:            envoyUnit(out.rule),
+		},
+		LimitRemaining:     uint32(max(0, out.res.Remaining)),
+		DurationUntilReset: durationpb.New(max(0, time.Until(out.res.ResetAt))),
+	}
+}
+
+// envoyUnit maps a rule's window onto Envoy's units. Windows that aren't a
+// whole unit, like 10 seconds, are UNKNOWN.
+func envoyUnit(rule store.Rule) rlspb.RateLimitResponse_RateLimit_Unit {
+	switch rule.Period {
+	case "day":
+		return rlspb.RateLimitResponse_RateLimit_DAY
+	case "week":
+		return rlspb.RateLimitResponse_RateLimit_WEEK
+	case "month":
+		return rlspb.RateLimitResponse_RateLimit_MONTH
+	}
+
+	switch rule.WindowSecs {
+	case 1:
+		return rlspb.RateLimitResponse_RateLimit_SECOND
+	case 60:
+		return rlspb.RateLimitResponse_RateLimit_MINUTE
+	case 3600:
+		return rlspb.RateLimitResponse_RateLimit_HOUR
+	case 86400:
+		return rlspb.RateLimitResponse_RateLimit_DAY
+	}
+	return rlspb.RateLimitResponse_RateLimit_UNKNOWN
+}
+
+// envoyHeaders are the x-ratelimit-* headers Envoy's own ratelimit service
+// sends, plus retry-after when the request is over the limit
+func envoyHeaders(out checkOutcome) []*rlspb.HeaderValue {
+	limit := strconv.Itoa(out.limit)
+	if out.rule.Period == "" && out.rule.WindowSecs > 0 {
+		limit = fmt.Sprintf("%s, %s;w=%d", limit, limit, out.rule.WindowSecs)
+	}
+
+	headers := []*rlspb.HeaderValue{
+		{Key: "x-ratelimit-limit", Value: limit},
+		{Key: "x-ratelimit-remaining", Value: strconv.Itoa(max(0, out.res.Remaining))},
+		{Key: "x-ratelimit-reset", Value: strconv.FormatInt(secondsCeil(time.Until(out.res.ResetAt)), 10)},
+	}
+	if !out.res.Allowed {
+		headers = append(headers, &rlspb.HeaderValue{
+			Key:   "retry-after",
+			Value: strconv.FormatInt(secondsCeil(out.res.RetryAfter), 10),
+		})
+	}
+	return headers
+}
+
+// secondsCeil is millisCeil for headers that only take whole seconds
+func secondsCeil(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return int64((d + time.Second - 1) / time.Second)
+}
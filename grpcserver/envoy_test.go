@@ -0,0 +1,189 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	rlspb "github.com/cynkin/rlaas/proto/envoy"
+	"github.com/cynkin/rlaas/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// envoyDesc builds a descriptor from key, value pairs
+func envoyDesc(kv ...string) *rlspb.RateLimitDescriptor {
+	desc := &rlspb.RateLimitDescriptor{}
+	for i := 0; i < len(kv); i += 2 {
+		desc.Entries = append(desc.Entries, &rlspb.RateLimitDescriptor_Entry{Key: kv[i], Value: kv[i+1]})
+	}
+	return desc
+}
+
+func TestEnvoyDescriptor(t *testing.T) {
+	tests := []struct {
+		name    string
+		domain  string
+		desc    *rlspb.RateLimitDescriptor
+		ruleID  string
+		want    store.Descriptors
+		wantErr codes.Code
+	}{
+		{name: "domain is the rule", domain: "ingress", desc: envoyDesc("remote_address", "10.0.0.1"), ruleID: "ingress", want: store.Descriptors{"remote_address": "10.0.0.1"}},
+		{name: "rule_id entry wins", domain: "ingress", desc: envoyDesc("rule_id", "login", "client_id", "acme"), ruleID: "login", want: store.Descriptors{"client_id": "acme"}},
+		{name: "empty client_id is no client", domain: "ingress", desc: envoyDesc("client_id", ""), ruleID: "ingress", want: store.Descriptors{}},
+		{name: "empty key", domain: "ingress", desc: envoyDesc("", "x"), wantErr: codes.InvalidArgument},
+		{name: "repeated key", domain: "ingress", desc: envoyDesc("path", "/a", "path", "/b"), wantErr: codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ruleID, d, err := envoyDescriptor(tt.domain, tt.desc)
+			if status.Code(err) != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if ruleID != tt.ruleID {
+				t.Errorf("rule ID %q, want %q", ruleID, tt.ruleID)
+			}
+			if len(d) != len(tt.want) {
+				t.Fatalf("descriptors %v, want %v", d, tt.want)
+			}
+			for k, v := range tt.want {
+				if d[k] != v {
+					t.Errorf("descriptors %v, want %v", d, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEnvoyUnit(t *testing.T) {
+	tests := []struct {
+		rule store.Rule
+		want rlspb.RateLimitResponse_RateLimit_Unit
+	}{
+		{rule: store.Rule{WindowSecs: 1}, want: rlspb.RateLimitResponse_RateLimit_SECOND},
+		{rule: store.Rule{WindowSecs: 60}, want: rlspb.RateLimitResponse_RateLimit_MINUTE},
+		{rule: store.Rule{WindowSecs: 3600}, want: rlspb.RateLimitResponse_RateLimit_HOUR},
+		{rule: store.Rule{WindowSecs: 86400}, want: rlspb.RateLimitResponse_RateLimit_DAY},
+		{rule: store.Rule{WindowSecs: 10}, want: rlspb.RateLimitResponse_RateLimit_UNKNOWN},
+		{rule: store.Rule{Period: "day"}, want: rlspb.RateLimitResponse_RateLimit_DAY},
+		{rule: store.Rule{Period: "week"}, want: rlspb.RateLimitResponse_RateLimit_WEEK},
+		{rule: store.Rule{Period: "month", WindowSecs: 60}, want: rlspb.RateLimitResponse_RateLimit_MONTH},
+	}
+
+	for _, tt := range tests {
+		if got := envoyUnit(tt.rule); got != tt.want {
+			t.Errorf("envoyUnit(window %d, period %q) = %v, want %v", tt.rule.WindowSecs, tt.rule.Period, got, tt.want)
+		}
+	}
+}
+
+func TestEnvoyHeaders(t *testing.T) {
+	tests := []struct {
+		name string
+		out  checkOutcome
+		want map[string]string
+	}{
+		{
+			name: "allowed, windowed rule",
+			out: checkOutcome{
+				rule: store.Rule{WindowSecs: 60}, limit: 10,
+				res: limiter.Result{Allowed: true, Remaining: 4, ResetAt: time.Now().Add(30 * time.Second)},
+			},
+			want: map[string]string{"x-ratelimit-limit": "10, 10;w=60", "x-ratelimit-remaining": "4", "x-ratelimit-reset": "30"},
+		},
+		{
+			name: "blocked, calendar rule has no window",
+			out: checkOutcome{
+				rule: store.Rule{Period: "day"}, limit: 100,
+				res: limiter.Result{Remaining: 0, RetryAfter: 1500 * time.Millisecond, ResetAt: time.Now().Add(time.Hour)},
+			},
+			want: map[string]string{"x-ratelimit-limit": "100", "x-ratelimit-remaining": "0", "x-ratelimit-reset": "3600", "retry-after": "2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[string]string{}
+			for _, h := range envoyHeaders(tt.out) {
+				got[h.Key] = h.Value
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("headers %v, want %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestShouldRateLimit(t *testing.T) {
+	s, _ := newTestServer(t, testBucket, testGCRA)
+	e := NewEnvoyServer(s)
+	ctx := context.Background()
+
+	check := func(req *rlspb.RateLimitRequest) *rlspb.RateLimitResponse {
+		t.Helper()
+		resp, err := e.ShouldRateLimit(ctx, req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	// Login's capacity of 3 goes in one request, the descriptor without a rule is OK
+	resp := check(&rlspb.RateLimitRequest{
+		Domain:      "ingress",
+		HitsAddend:  3,
+		Descriptors: []*rlspb.RateLimitDescriptor{envoyDesc("rule_id", "login", "client_id", "acme"), envoyDesc("path", "/")},
+	})
+	if resp.OverallCode != rlspb.RateLimitResponse_OK {
+		t.Fatalf("overall %v, want OK", resp.OverallCode)
+	}
+	if st := resp.Statuses[0]; st.CurrentLimit.GetRequestsPerUnit() != 3 || st.CurrentLimit.GetUnit() != rlspb.RateLimitResponse_RateLimit_HOUR || st.LimitRemaining != 0 {
+		t.Errorf("login status %v, want 3 per hour with 0 remaining", st)
+	}
+	if st := resp.Statuses[1]; st.Code != rlspb.RateLimitResponse_OK || st.CurrentLimit != nil {
+		t.Errorf("unconfigured descriptor status %v, want OK without a limit", st)
+	}
+
+	// Now over, and the request as a whole with it
+	resp = check(&rlspb.RateLimitRequest{
+		Domain:      "ingress",
+		Descriptors: []*rlspb.RateLimitDescriptor{envoyDesc("rule_id", "search", "client_id", "acme"), envoyDesc("rule_id", "login", "client_id", "acme")},
+	})
+	if resp.OverallCode != rlspb.RateLimitResponse_OVER_LIMIT {
+		t.Fatalf("overall %v, want OVER_LIMIT", resp.OverallCode)
+	}
+	if resp.Statuses[0].Code != rlspb.RateLimitResponse_OK || resp.Statuses[1].Code != rlspb.RateLimitResponse_OVER_LIMIT {
+		t.Errorf("statuses %v, want search OK and login OVER_LIMIT", resp.Statuses)
+	}
+	headers := map[string]string{}
+	for _, h := range resp.ResponseHeadersToAdd {
+		headers[h.Key] = h.Value
+	}
+	if headers["x-ratelimit-remaining"] != "0" || headers["retry-after"] == "" {
+		t.Errorf("headers %v, want the blocked login's", headers)
+	}
+
+	// A descriptor's own hits_addend wins, and more than the rule ever allows is refused
+	_, err := e.ShouldRateLimit(ctx, &rlspb.RateLimitRequest{
+		Domain: "ingress",
+		Descriptors: []*rlspb.RateLimitDescriptor{{
+			Entries:    envoyDesc("rule_id", "search", "client_id", "beta").Entries,
+			HitsAddend: wrapperspb.UInt64(5),
+		}},
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("oversized hits_addend: err = %v, want InvalidArgument", err)
+	}
+}
@@ -0,0 +1,145 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/cynkin/rlaas/metrics"
+	"github.com/cynkin/rlaas/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errBackendDown stands in for the backend's error while health checks say it is down
+var errBackendDown = errors.New("backend marked unhealthy")
+
+// viaBackend runs op against the backend unless it is known to be down, and
+// reports connection trouble to the health checker. Errors from the command
+// itself come back as they are without marking the backend down.
+func (s *RateLimiterServer) viaBackend(ctx context.Context, op func() error) error {
+	health := s.failure.Health
+	if health == nil {
+		return op()
+	}
+	if !health.Healthy() {
+		return errBackendDown
+	}
+
+	err := op()
+	// A caller giving up says nothing about the backend
+	if err != nil && ctx.Err() == nil && store.IsUnavailable(err) {
+		health.ReportFailure(err)
+	}
+	return err
+}
+
+// allow runs a single check through viaBackend
+func (s *RateLimiterServer) allow(ctx context.Context, l limiter.Limiter, clientKey string, cost int) (limiter.Result, error) {
+	var res limiter.Result
+	err := s.viaBackend(ctx, func() (err error) {
+		res, err = l.Allow(ctx, clientKey, cost)
+		return err
+	})
+	return res, err
+}
+
+// allowBatch is allow for a batch: the whole batch skips the backend while it is down
+func (s *RateLimiterServer) allowBatch(ctx context.Context, checks []store.BatchCheck) ([]limiter.Result, []error) {
+	health := s.failure.Health
+	if health != nil && !health.Healthy() {
+		errs := make([]error, len(checks))
+		for i := range errs {
+			errs[i] = errBackendDown
+		}
+		return make([]limiter.Result, len(checks)), errs
+	}
+
+	results, errs := store.NewAtomicBatch(s.redisClient).Allow(ctx, checks)
+	if health != nil && ctx.Err() == nil {
+		for _, err := range errs {
+			if store.IsUnavailable(err) {
+				health.ReportFailure(err)
+				break
+			}
+		}
+	}
+	return results, errs
+}
+
+// How long a fail-closed caller is told to wait. The backend is down, so there
+// is no real reset time to report.
+const failClosedRetryAfter = time.Second
+
+// degradedDecision answers a check the backend failed on, following the rule's
+// failure mode, or the server default when the rule has none.
+func (s *RateLimiterServer) degradedDecision(ctx context.Context, rule store.Rule, clientKey string, cost int, cause error) (limiter.Result, error) {
+	mode := rule.FailureMode
+	if mode == "" {
+		mode = s.failure.DefaultMode
+	}
+
+	// Health checks already logged the outage
+	if cause != errBackendDown {
+		fmt.Printf("Rate limiter backend error for rule %s, failing %s: %v\n", rule.RuleID, mode, cause)
+	}
+
+	var res limiter.Result
+	switch mode {
+	case store.FailOpen:
+		// Nothing was counted, so the client is at its full allowance now
+		res = limiter.Result{Allowed: true, ResetAt: time.Now()}
+	case store.FailClosed:
+		res = limiter.Result{Allowed: false, RetryAfter: failClosedRetryAfter, ResetAt: time.Now().Add(failClosedRetryAfter)}
+	case store.FailLocalFallback:
+		l, err := s.fallbackLimiter(rule)
+		if err != nil {
+			return limiter.Result{}, err
+		}
+		if res, err = l.Allow(ctx, clientKey, cost); err != nil {
+			return limiter.Result{}, fmt.Errorf("fallback limiter error: %w", err)
+		}
+	default:
+		return limiter.Result{}, status.Errorf(codes.Unavailable, "rate limiter backend error: %v", cause)
+	}
+
+	result := "allowed"
+	if !res.Allowed {
+		result = "blocked"
+	}
+	metrics.DegradedDecisionsTotal.With(prometheus.Labels{
+		"rule_id":      rule.RuleID,
+		"failure_mode": mode,
+		"result":       result,
+	}).Inc()
+
+	return res, nil
+}
+
+// fallbackLimiter builds the rule's in-memory limiter. Each instance only sees
+// its own share of the traffic, so it gets its share of the limit. Algorithms
+// the memory backend doesn't have name the one standing in for them.
+func (s *RateLimiterServer) fallbackLimiter(rule store.Rule) (limiter.Limiter, error) {
+	alg, cfg, err := s.algorithmFor(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := s.failure.Replicas
+	cfg.Limit = max(1, cfg.Limit/replicas)
+	cfg.Capacity = max(1, cfg.Capacity/replicas)
+	cfg.RefillRate /= float64(replicas)
+
+	name := alg.LocalFallback
+	if name == "" {
+		name = alg.Name
+	}
+	l, err := s.fallback.New(name, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("rule %q has no local fallback: %w", rule.RuleID, err)
+	}
+	return l, nil
+}
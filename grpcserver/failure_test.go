@@ -0,0 +1,105 @@
+package grpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/cynkin/rlaas/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestDegradedDecision(t *testing.T) {
+	fixed := store.Rule{RuleID: "login", Algorithm: "fixed_window", Limit: 4, WindowSecs: 60}
+	sliding := store.Rule{RuleID: "search", Algorithm: "sliding_window", Limit: 4, WindowSecs: 60}
+	// Capacity 4, refilled at 4 per minute
+	bucket := store.Rule{RuleID: "upload", Algorithm: "token_bucket", Limit: 4, WindowSecs: 60}
+	gcra := store.Rule{RuleID: "api", Algorithm: "gcra", Limit: 4, WindowSecs: 60, Capacity: 8}
+	leaky := store.Rule{RuleID: "jobs", Algorithm: "leaky_bucket", Limit: 4, WindowSecs: 60}
+	swc := store.Rule{RuleID: "feed", Algorithm: "sliding_window_counter", Limit: 4, WindowSecs: 60}
+
+	withMode := func(rule store.Rule, mode string) store.Rule {
+		rule.FailureMode = mode
+		return rule
+	}
+
+	tests := []struct {
+		name        string
+		rule        store.Rule
+		defaultMode string
+		replicas    int
+		checks      int           // checks in each burst
+		allowed     int           // how many of the first burst get through
+		advance     time.Duration // before the second burst
+		allowedThen int
+	}{
+		{name: "open lets everything through", rule: fixed, defaultMode: store.FailOpen, replicas: 1, checks: 10, allowed: 10, allowedThen: 10},
+		{name: "closed blocks everything", rule: fixed, defaultMode: store.FailClosed, replicas: 1, checks: 3, allowed: 0, advance: time.Minute, allowedThen: 0},
+		{name: "rule's own mode wins over the default", rule: withMode(fixed, store.FailOpen), defaultMode: store.FailClosed, replicas: 1, checks: 6, allowed: 6, allowedThen: 6},
+
+		{name: "fixed window, one replica", rule: fixed, defaultMode: store.FailLocalFallback, replicas: 1, checks: 6, allowed: 4, advance: time.Minute, allowedThen: 4},
+		{name: "fixed window, two replicas", rule: fixed, defaultMode: store.FailLocalFallback, replicas: 2, checks: 6, allowed: 2, advance: time.Minute, allowedThen: 2},
+		{name: "sliding window, one replica", rule: sliding, defaultMode: store.FailLocalFallback, replicas: 1, checks: 6, allowed: 4, advance: time.Minute, allowedThen: 4},
+		{name: "sliding window, two replicas", rule: sliding, defaultMode: store.FailLocalFallback, replicas: 2, checks: 6, allowed: 2, advance: time.Minute, allowedThen: 2},
+		// Half a minute refills two tokens with one replica, one with two
+		{name: "token bucket, one replica", rule: bucket, defaultMode: store.FailLocalFallback, replicas: 1, checks: 6, allowed: 4, advance: 30 * time.Second, allowedThen: 2},
+		{name: "token bucket, two replicas", rule: bucket, defaultMode: store.FailLocalFallback, replicas: 2, checks: 6, allowed: 2, advance: 30 * time.Second, allowedThen: 1},
+
+		// No memory gcra, leaky bucket or sliding window counter: a fixed window
+		// over the rule's limit and window stands in
+		{name: "gcra falls back to a fixed window, one replica", rule: gcra, defaultMode: store.FailLocalFallback, replicas: 1, checks: 10, allowed: 4, advance: time.Minute, allowedThen: 4},
+		{name: "gcra falls back to a fixed window, two replicas", rule: gcra, defaultMode: store.FailLocalFallback, replicas: 2, checks: 10, allowed: 2, advance: time.Minute, allowedThen: 2},
+		{name: "leaky bucket falls back to a fixed window", rule: leaky, defaultMode: store.FailLocalFallback, replicas: 2, checks: 6, allowed: 2, advance: time.Minute, allowedThen: 2},
+		{name: "sliding window counter falls back to a fixed window", rule: swc, defaultMode: store.FailLocalFallback, replicas: 2, checks: 6, allowed: 2, advance: time.Minute, allowedThen: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Minute aligned, so fixed windows start with the test
+			clock := limiter.NewManualClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+			s := &RateLimiterServer{
+				registry: store.NewRedisRegistry(nil), // only consulted for the rule's algorithm
+				failure:  FailurePolicy{DefaultMode: tt.defaultMode, Replicas: tt.replicas},
+				fallback: limiter.NewMemoryRegistry(limiter.NewMemoryStore(clock)),
+			}
+			ctx := context.Background()
+
+			burst := func() int {
+				t.Helper()
+				allowed := 0
+				for range tt.checks {
+					res, err := s.degradedDecision(ctx, tt.rule, "client:{acme}", 1, errBackendDown)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if res.Allowed {
+						allowed++
+					} else if res.RetryAfter <= 0 {
+						t.Errorf("blocked with retry_after %v, want a wait", res.RetryAfter)
+					}
+				}
+				return allowed
+			}
+
+			if got := burst(); got != tt.allowed {
+				t.Errorf("first burst: %d of %d allowed, want %d", got, tt.checks, tt.allowed)
+			}
+			clock.Advance(tt.advance)
+			if got := burst(); got != tt.allowedThen {
+				t.Errorf("after %v: %d of %d allowed, want %d", tt.advance, got, tt.checks, tt.allowedThen)
+			}
+		})
+	}
+}
+
+func TestDegradedDecisionUnknownMode(t *testing.T) {
+	s := &RateLimiterServer{failure: FailurePolicy{DefaultMode: "bogus", Replicas: 1}}
+	rule := store.Rule{RuleID: "login", Algorithm: "fixed_window", Limit: 4, WindowSecs: 60}
+
+	_, err := s.degradedDecision(context.Background(), rule, "login:{acme}", 1, errBackendDown)
+	if status.Code(err) != codes.Unavailable {
+		t.Errorf("err = %v, want Unavailable", err)
+	}
+}
@@ -0,0 +1,114 @@
+package grpcserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cynkin/rlaas/metrics"
+	pb "github.com/cynkin/rlaas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpCheckRequest is CheckLimitRequest for JSON callers, descriptors as an object
+type httpCheckRequest struct {
+	ClientID    string            `json:"client_id"`
+	RuleID      string            `json:"rule_id"`
+	Cost        int32             `json:"cost"`
+	Descriptors map[string]string `json:"descriptors"` // e.g. {"ip": "10.0.0.1", "method": "POST"}
+}
+
+type httpCheckResponse struct {
+	Allowed       bool   `json:"allowed"`
+	Remaining     int    `json:"remaining"`
+	RetryAfterMs  int64  `json:"retry_after_ms"`
+	Algorithm     string `json:"algorithm"`
+	ResetAtMs     int64  `json:"reset_at_ms,omitempty"`
+	Degraded      bool   `json:"degraded"`
+	MatchedRuleID string `json:"matched_rule_id"`
+}
+
+// HTTPHandler serves POST /check, the same decision as CheckLimit for callers
+// that can't use gRPC. Blocked checks are a 429 with Retry-After, and counted
+// checks carry the RateLimit-Limit/Remaining/Reset headers.
+func (s *RateLimiterServer) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /check", s.httpCheck)
+	return mux
+}
+
+func (s *RateLimiterServer) httpCheck(w http.ResponseWriter, r *http.Request) {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	var req httpCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	cost := int(req.Cost)
+	if cost < 0 {
+		http.Error(w, "cost must not be negative", http.StatusBadRequest)
+		return
+	}
+	if cost == 0 {
+		cost = 1
+	}
+
+	pairs := make([]*pb.Descriptor, 0, len(req.Descriptors))
+	for key, value := range req.Descriptors {
+		pairs = append(pairs, &pb.Descriptor{Key: key, Value: value})
+	}
+	descriptors, err := descriptorsOf(req.ClientID, pairs)
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), http.StatusBadRequest)
+		return
+	}
+
+	out, err := s.check(r.Context(), req.RuleID, req.ClientID, descriptors, cost)
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpStatus(err))
+		return
+	}
+
+	resp := httpCheckResponse{Allowed: true, Algorithm: out.rule.Algorithm}
+	if out.matched {
+		resp = httpCheckResponse{
+			Allowed:       out.res.Allowed,
+			Remaining:     out.res.Remaining,
+			RetryAfterMs:  millisCeil(out.res.RetryAfter),
+			Algorithm:     out.rule.Algorithm,
+			ResetAtMs:     out.res.ResetAt.UnixMilli(),
+			Degraded:      out.degraded,
+			MatchedRuleID: out.rule.RuleID,
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Limit", strconv.Itoa(out.limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(max(0, out.res.Remaining)))
+		h.Set("RateLimit-Reset", strconv.FormatInt(secondsCeil(time.Until(out.res.ResetAt)), 10))
+		if !out.res.Allowed {
+			h.Set("Retry-After", strconv.FormatInt(secondsCeil(out.res.RetryAfter), 10))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !resp.Allowed {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// httpStatus maps the gRPC codes check returns onto HTTP statuses
+func httpStatus(err error) int {
+	switch status.Code(err) {
+	case codes.InvalidArgument, codes.FailedPrecondition:
+		return http.StatusBadRequest
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
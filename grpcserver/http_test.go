@@ -0,0 +1,84 @@
+package grpcserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPCheck(t *testing.T) {
+	s, _ := newTestServer(t, testBucket)
+	h := s.HTTPHandler()
+
+	// In order, against the same bucket of 3 refilling one every 20 minutes
+	tests := []struct {
+		name        string
+		body        string
+		wantStatus  int
+		wantHeaders map[string]string // "" for must not be set
+	}{
+		{
+			name:        "allowed",
+			body:        `{"rule_id": "login", "client_id": "acme", "cost": 2}`,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"RateLimit-Limit": "3", "RateLimit-Remaining": "1", "RateLimit-Reset": "2400", "Retry-After": ""},
+		},
+		{
+			name:        "blocked",
+			body:        `{"rule_id": "login", "client_id": "acme", "cost": 2}`,
+			wantStatus:  http.StatusTooManyRequests,
+			wantHeaders: map[string]string{"RateLimit-Limit": "3", "RateLimit-Remaining": "1", "Retry-After": "1200"},
+		},
+		{
+			name:        "other client has its own bucket",
+			body:        `{"rule_id": "login", "client_id": "beta"}`,
+			wantStatus:  http.StatusOK,
+			wantHeaders: map[string]string{"RateLimit-Remaining": "2"},
+		},
+		{
+			name:        "invalid body",
+			body:        `{"rule_id": `,
+			wantStatus:  http.StatusBadRequest,
+			wantHeaders: map[string]string{"RateLimit-Limit": ""},
+		},
+		{
+			name:       "negative cost",
+			body:       `{"rule_id": "login", "client_id": "acme", "cost": -1}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "cost over capacity",
+			body:       `{"rule_id": "login", "client_id": "acme", "cost": 4}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/check", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			for k, v := range tt.wantHeaders {
+				if got := rec.Header().Get(k); got != v {
+					t.Errorf("%s = %q, want %q", k, got, v)
+				}
+			}
+			if rec.Code != http.StatusOK && rec.Code != http.StatusTooManyRequests {
+				return
+			}
+
+			var resp httpCheckResponse
+			if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Allowed != (rec.Code == http.StatusOK) || resp.MatchedRuleID != "login" {
+				t.Errorf("response %+v for status %d", resp, rec.Code)
+			}
+		})
+	}
+}
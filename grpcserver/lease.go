@@ -0,0 +1,171 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/cynkin/rlaas/metrics"
+	pb "github.com/cynkin/rlaas/proto"
+	"github.com/cynkin/rlaas/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultQuotaLeaseTTL = time.Second
+	// Longer leases let a client sit on permits well past the window they came from
+	maxQuotaLeaseTTL = time.Minute
+)
+
+func (s *RateLimiterServer) LeaseQuota(ctx context.Context, req *pb.LeaseQuotaRequest) (*pb.LeaseQuotaResponse, error) {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	requestStart := time.Now()
+
+	if s.redisClient == nil {
+		return nil, status.Error(codes.Unimplemented, "quota leases need the redis backend")
+	}
+
+	if req.Permits <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "permits must be positive, got %d", req.Permits)
+	}
+
+	ttl := time.Duration(req.TtlMs) * time.Millisecond
+	if ttl <= 0 {
+		ttl = defaultQuotaLeaseTTL
+	}
+	if ttl > maxQuotaLeaseTTL {
+		return nil, status.Errorf(codes.InvalidArgument, "ttl_ms must be at most %d", maxQuotaLeaseTTL.Milliseconds())
+	}
+
+	rule, err := s.ruleStore.GetRule(ctx, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, fmt.Errorf("rule lookup failed: %w", err)
+	}
+
+	l, err := s.limiterFor(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	// Same counters as CheckLimit, so leased permits and direct checks add up
+	clientKey, err := clientKeyFor(rule, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	redisStart := time.Now()
+	leasedAt := time.Now()
+	granted := int(req.Permits)
+
+	var res limiter.Result
+	var lease store.QuotaLease
+	err = s.viaBackend(ctx, func() (err error) {
+		res, err = l.Allow(ctx, clientKey, granted)
+
+		// Not enough for the whole batch, settle for what is left
+		if err == nil && !res.Allowed && res.Remaining > 0 {
+			granted = res.Remaining
+			res, err = l.Allow(ctx, clientKey, granted)
+		}
+
+		if err == nil && res.Allowed {
+			lease, err = store.NewQuotaLeaseStore(s.redisClient).Grant(ctx, clientKey, granted, leasedAt, ttl)
+
+			// The permits are counted already, without a lease nobody could
+			// return them. Best effort, the grant error is what matters.
+			if refunder, ok := l.(limiter.Refunder); ok && err != nil {
+				refunder.Refund(context.WithoutCancel(ctx), clientKey, granted, leasedAt)
+			}
+		}
+		return err
+	})
+
+	metrics.RedisDuration.With(prometheus.Labels{
+		"operation": "lease_quota",
+	}).Observe(time.Since(redisStart).Seconds())
+
+	// Leases are kept in the backend, there is nothing to hand out without it
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "rate limiter backend error: %v", err)
+	}
+
+	recordDecision(rule, res.Allowed, requestStart)
+	s.logRequests(requestLog{clientID: req.ClientId, ruleID: req.RuleId, allowed: res.Allowed})
+
+	if !res.Allowed {
+		return &pb.LeaseQuotaResponse{
+			Remaining:    int32(res.Remaining),
+			RetryAfterMs: millisCeil(res.RetryAfter),
+			Algorithm:    rule.Algorithm,
+		}, nil
+	}
+
+	return &pb.LeaseQuotaResponse{
+		Granted:     int32(granted),
+		LeaseId:     lease.ID,
+		ExpiresAtMs: lease.ExpiresAt.UnixMilli(),
+		Remaining:   int32(res.Remaining),
+		Algorithm:   rule.Algorithm,
+	}, nil
+}
+
+func (s *RateLimiterServer) ReturnQuota(ctx context.Context, req *pb.ReturnQuotaRequest) (*pb.ReturnQuotaResponse, error) {
+	if s.redisClient == nil {
+		return nil, status.Error(codes.Unimplemented, "quota leases need the redis backend")
+	}
+
+	if req.LeaseId == "" {
+		return nil, status.Error(codes.InvalidArgument, "lease_id is required")
+	}
+	if req.Unused < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "unused must not be negative, got %d", req.Unused)
+	}
+
+	rule, err := s.ruleStore.GetRule(ctx, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, fmt.Errorf("rule lookup failed: %w", err)
+	}
+
+	l, err := s.limiterFor(rule)
+	if err != nil {
+		return nil, err
+	}
+
+	clientKey, err := clientKeyFor(rule, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, err
+	}
+
+	redisStart := time.Now()
+	defer func() {
+		metrics.RedisDuration.With(prometheus.Labels{
+			"operation": "return_quota",
+		}).Observe(time.Since(redisStart).Seconds())
+	}()
+
+	// Taking the lease even when nothing is unused closes it out
+	lease, ok, err := store.NewQuotaLeaseStore(s.redisClient).Take(ctx, clientKey, req.LeaseId)
+	if err != nil {
+		return nil, fmt.Errorf("quota lease error: %w", err)
+	}
+	if !ok || req.Unused == 0 {
+		return &pb.ReturnQuotaResponse{}, nil
+	}
+
+	refunder, ok := l.(limiter.Refunder)
+	if !ok {
+		return &pb.ReturnQuotaResponse{}, nil
+	}
+
+	returned, err := refunder.Refund(ctx, clientKey, min(int(req.Unused), lease.Permits), lease.LeasedAt)
+	if err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	return &pb.ReturnQuotaResponse{Returned: int32(returned)}, nil
+}
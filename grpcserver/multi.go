@@ -0,0 +1,134 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/cynkin/rlaas/metrics"
+	pb "github.com/cynkin/rlaas/proto"
+	"github.com/cynkin/rlaas/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *RateLimiterServer) CheckLimits(ctx context.Context, req *pb.CheckLimitsRequest) (*pb.CheckLimitsResponse, error) {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	requestStart := time.Now()
+
+	// All-or-nothing across rules is a single Lua script
+	if s.redisClient == nil {
+		return nil, status.Error(codes.Unimplemented, "multi-rule checks need the redis backend")
+	}
+
+	if len(req.RuleIds) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one rule_id is required")
+	}
+
+	cost := int(req.Cost)
+	if cost < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "cost must not be negative, got %d", req.Cost)
+	}
+	if cost == 0 {
+		cost = 1
+	}
+
+	// Resolve every rule up front. Unknown IDs fall back to "default", so two
+	// of them would hit the same counter twice — refuse that instead. Pattern
+	// rules count per rule ID, so those can appear more than once.
+	checks := make([]store.MultiCheck, 0, len(req.RuleIds))
+	seen := make(map[string]bool, len(req.RuleIds))
+	for _, ruleID := range req.RuleIds {
+		rule, err := s.ruleStore.GetRule(ctx, ruleID, req.ClientId)
+		if err != nil {
+			return nil, fmt.Errorf("rule lookup failed: %w", err)
+		}
+		counterID := rule.CounterID(ruleID)
+		if seen[counterID] {
+			return nil, status.Errorf(codes.InvalidArgument, "rule %q resolved more than once", rule.RuleID)
+		}
+		l, err := s.limiterFor(rule)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.checkCost(rule, cost); err != nil {
+			return nil, err
+		}
+		clientKey, err := clientKeyFor(rule, ruleID, req.ClientId)
+		if err != nil {
+			return nil, err
+		}
+		seen[counterID] = true
+
+		checks = append(checks, store.MultiCheck{
+			Rule:      rule,
+			Limiter:   l,
+			ClientKey: clientKey,
+		})
+	}
+
+	redisStart := time.Now()
+	var allowed bool
+	var results []limiter.Result
+	err := s.viaBackend(ctx, func() (err error) {
+		allowed, results, err = store.NewAtomicMulti(s.redisClient).Allow(ctx, checks, cost)
+		return err
+	})
+	metrics.RedisDuration.With(prometheus.Labels{
+		"operation": "multi_rule",
+	}).Observe(time.Since(redisStart).Seconds())
+
+	if errors.Is(err, store.ErrNotMultiRule) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	// Backend trouble: each rule's failure mode decides for that rule, and the
+	// request passes if all of them let it. Degraded checks aren't
+	// all-or-nothing, a local-fallback rule counts even when another blocks.
+	degraded := err != nil
+	if degraded {
+		cause := err
+		allowed = true
+		results = make([]limiter.Result, len(checks))
+		for i, check := range checks {
+			results[i], err = s.degradedDecision(ctx, check.Rule, check.ClientKey, cost, cause)
+			if err != nil {
+				return nil, err
+			}
+			allowed = allowed && results[i].Allowed
+		}
+	}
+
+	resp := &pb.CheckLimitsResponse{Allowed: allowed, Degraded: degraded}
+	logs := make([]requestLog, 0, len(checks))
+	for i, check := range checks {
+		rule, res := check.Rule, results[i]
+
+		retryAfterMs := millisCeil(res.RetryAfter)
+		if retryAfterMs > resp.RetryAfterMs {
+			resp.RetryAfterMs = retryAfterMs
+		}
+
+		resp.Results = append(resp.Results, &pb.RuleResult{
+			RuleId:       rule.RuleID,
+			Allowed:      res.Allowed,
+			Remaining:    int32(res.Remaining),
+			RetryAfterMs: retryAfterMs,
+			Algorithm:    rule.Algorithm,
+			ResetAtMs:    res.ResetAt.UnixMilli(),
+			Degraded:     degraded,
+		})
+
+		// The request as a whole decides what each rule counts it as
+		recordDecision(rule, allowed, requestStart)
+		logs = append(logs, requestLog{clientID: req.ClientId, ruleID: rule.RuleID, allowed: allowed})
+	}
+	s.logRequests(logs...)
+
+	return resp, nil
+}
@@ -0,0 +1,76 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/cynkin/rlaas/metrics"
+	pb "github.com/cynkin/rlaas/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *RateLimiterServer) Refund(ctx context.Context, req *pb.RefundRequest) (*pb.RefundResponse, error) {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	amount := int(req.Amount)
+	if amount < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "amount must not be negative, got %d", req.Amount)
+	}
+	if amount == 0 {
+		amount = 1
+	}
+
+	// Units can't have been consumed in the future
+	consumedAt := time.Now()
+	if req.ConsumedAtMs > 0 && req.ConsumedAtMs < consumedAt.UnixMilli() {
+		consumedAt = time.UnixMilli(req.ConsumedAtMs)
+	}
+
+	descriptors, err := descriptorsOf(req.ClientId, req.Descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	// No falling back to "default" either, a typo would hand units back to it
+	rule, ok, err := s.ruleStore.FindRule(ctx, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, fmt.Errorf("rule lookup failed: %w", err)
+	}
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no rule matches %q", req.RuleId)
+	}
+	if err := requireKeyBy(rule, descriptors); err != nil {
+		return nil, err
+	}
+
+	l, err := s.limiterFor(rule)
+	if err != nil {
+		return nil, err
+	}
+	refunder, ok := l.(limiter.Refunder)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "algorithm %q can't refund", rule.Algorithm)
+	}
+
+	// Checks made while the backend was down never reached its counters
+	if health := s.failure.Health; health != nil && !health.Healthy() {
+		return nil, status.Error(codes.Unavailable, "rate limiter backend is unavailable")
+	}
+
+	redisStart := time.Now()
+	refunded, err := refunder.Refund(ctx, rule.CounterKey(req.RuleId, descriptors), amount, consumedAt)
+	metrics.RedisDuration.With(prometheus.Labels{
+		"operation": "refund",
+	}).Observe(time.Since(redisStart).Seconds())
+
+	if err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	return &pb.RefundResponse{Refunded: int32(refunded)}, nil
+}
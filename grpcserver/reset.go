@@ -0,0 +1,56 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	pb "github.com/cynkin/rlaas/proto"
+	"github.com/cynkin/rlaas/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *RateLimiterServer) ResetLimit(ctx context.Context, req *pb.ResetLimitRequest) (*pb.ResetLimitResponse, error) {
+	// No falling back to "default" here, a typo would wipe its counters
+	rule, ok, err := s.ruleStore.FindRule(ctx, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, fmt.Errorf("rule lookup failed: %w", err)
+	}
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no rule matches %q", req.RuleId)
+	}
+
+	// Nothing picks out a client, so every client goes
+	if req.ClientId == "" && len(req.Descriptors) == 0 {
+		cleared, err := s.resetter.ResetAll(ctx, rule.CounterID(req.RuleId))
+		if err != nil {
+			return nil, resetError(err)
+		}
+		return &pb.ResetLimitResponse{MatchedRuleId: rule.RuleID, KeysCleared: int32(cleared)}, nil
+	}
+
+	descriptors, err := descriptorsOf(req.ClientId, req.Descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := requireKeyBy(rule, descriptors); err != nil {
+		return nil, err
+	}
+
+	if err := s.resetter.Reset(ctx, rule, rule.CounterKey(req.RuleId, descriptors)); err != nil {
+		return nil, resetError(err)
+	}
+	return &pb.ResetLimitResponse{MatchedRuleId: rule.RuleID}, nil
+}
+
+func resetError(err error) error {
+	switch {
+	case errors.Is(err, store.ErrNoCounters):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, store.ErrResetAllUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	}
+	return fmt.Errorf("reset error: %w", err)
+}
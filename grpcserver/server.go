@@ -2,15 +2,19 @@ package grpcserver
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/cynkin/rlaas/limiter"
 	"github.com/cynkin/rlaas/metrics"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	pb "github.com/cynkin/rlaas/proto"
 	"github.com/cynkin/rlaas/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Rule defines the rate limiting configuration for a specific use case
@@ -45,17 +49,92 @@ var DefaultRules = map[string]Rule{
 
 type RateLimiterServer struct {
 	pb.UnimplementedRateLimiterServer	// embedding for forward compatibility
-	redisClient *redis.Client
+	redisClient redis.UniversalClient // nil with the memory backend
+	registry 	*limiter.Registry
 	ruleStore  	*store.RuleStore
 	db 			*pgxpool.Pool
+	failure     FailurePolicy
+	fallback    *limiter.Registry // in-memory limiters for "local-fallback" rules
+	resetter    *store.CounterResetter
 }
 
-func NewRateLimiterServer(redisClient *redis.Client, ruleStore *store.RuleStore, db *pgxpool.Pool) *RateLimiterServer {
+// FailurePolicy is how the server keeps answering while the backend is down
+type FailurePolicy struct {
+	DefaultMode string             // for rules without their own failure_mode
+	Health      *store.RedisHealth // checks skip the backend while it is unhealthy, nil to always try it
+	Replicas    int                // instances sharing the load, local-fallback limits are split between them
+}
+
+func NewRateLimiterServer(redisClient redis.UniversalClient, registry *limiter.Registry, ruleStore *store.RuleStore, db *pgxpool.Pool, failure FailurePolicy) *RateLimiterServer {
+	if failure.Replicas < 1 {
+		failure.Replicas = 1
+	}
+
 	return &RateLimiterServer{
 		redisClient: redisClient,
+		registry:    registry,
 		ruleStore:   ruleStore,
 		db:          db,
+		failure:     failure,
+		fallback:    limiter.NewMemoryRegistry(limiter.NewMemoryStore(limiter.SystemClock)),
+		resetter:    store.NewCounterResetter(redisClient, registry),
+	}
+}
+
+// limiterFor builds the limiter a rule's algorithm names. Algorithms that don't
+// limit request rate, like in_flight, are a FailedPrecondition for the caller.
+func (s *RateLimiterServer) limiterFor(rule store.Rule) (limiter.Limiter, error) {
+	cfg, err := rule.Config()
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", rule.RuleID, err)
+	}
+
+	l, err := s.registry.New(rule.Algorithm, cfg)
+	if errors.Is(err, limiter.ErrNotRateLimiter) {
+		return nil, status.Errorf(codes.FailedPrecondition,
+			"rule %q limits concurrency, use AcquireConcurrency", rule.RuleID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("rule %q: %w", rule.RuleID, err)
+	}
+	return l, nil
+}
+
+// algorithmFor looks up the rule's algorithm and the settings it runs the rule
+// with, defaults filled in
+func (s *RateLimiterServer) algorithmFor(rule store.Rule) (limiter.Algorithm, limiter.Config, error) {
+	alg, ok := s.registry.Get(rule.Algorithm)
+	if !ok {
+		return limiter.Algorithm{}, limiter.Config{}, fmt.Errorf("rule %q: unknown algorithm %q", rule.RuleID, rule.Algorithm)
+	}
+	cfg, err := rule.Config()
+	if err != nil {
+		return limiter.Algorithm{}, limiter.Config{}, fmt.Errorf("rule %q: %w", rule.RuleID, err)
+	}
+	return alg, alg.Configure(cfg), nil
+}
+
+// ruleLimit is the most a client can have available under the rule, as its
+// algorithm counts it: the capacity of a bucket, the limit of a window
+func (s *RateLimiterServer) ruleLimit(rule store.Rule) int {
+	alg, cfg, err := s.algorithmFor(rule)
+	if err != nil {
+		return rule.Limit
 	}
+	return alg.Limit(cfg)
+}
+
+// checkCost refuses costs the rule's algorithm could never allow, which would
+// otherwise be blocked with a retry_after that never comes true
+func (s *RateLimiterServer) checkCost(rule store.Rule, cost int) error {
+	alg, cfg, err := s.algorithmFor(rule)
+	if err != nil {
+		return err
+	}
+	if err := alg.CheckCost(cfg, cost); err != nil {
+		return status.Errorf(codes.InvalidArgument, "rule %q: %v", rule.RuleID, err)
+	}
+	return nil
 }
 
 func (s *RateLimiterServer) CheckLimit(ctx context.Context, req *pb.CheckLimitRequest) (*pb.CheckLimitResponse, error) {
@@ -63,44 +142,117 @@ func (s *RateLimiterServer) CheckLimit(ctx context.Context, req *pb.CheckLimitRe
 	metrics.ActiveConnections.Inc()
 	defer metrics.ActiveConnections.Dec()
 
+	return s.checkLimit(ctx, req)
+}
+
+// checkLimit is CheckLimit without the connection tracking, for the stream
+// to run each of its checks through
+func (s *RateLimiterServer) checkLimit(ctx context.Context, req *pb.CheckLimitRequest) (*pb.CheckLimitResponse, error) {
+	// Requests without an explicit cost count as one unit
+	cost := int(req.Cost)
+	if cost < 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "cost must not be negative, got %d", req.Cost)
+	}
+	if cost == 0 {
+		cost = 1
+	}
+
+	descriptors, err := descriptorsOf(req.ClientId, req.Descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := s.check(ctx, req.RuleId, req.ClientId, descriptors, cost)
+	if err != nil {
+		return nil, err
+	}
+
+	// The rule doesn't cover this request (say, it only limits POST), nothing was counted
+	if !out.matched {
+		return &pb.CheckLimitResponse{Allowed: true, Algorithm: out.rule.Algorithm}, nil
+	}
+
+	return &pb.CheckLimitResponse{
+		Allowed:       out.res.Allowed,
+		Remaining:     int32(out.res.Remaining),
+		RetryAfterMs:  millisCeil(out.res.RetryAfter),
+		Algorithm:     out.rule.Algorithm,
+		ResetAtMs:     out.res.ResetAt.UnixMilli(),
+		Degraded:      out.degraded,
+		MatchedRuleId: out.rule.RuleID,
+	}, nil
+}
+
+// checkOutcome is what a single check decided
+type checkOutcome struct {
+	rule     store.Rule
+	matched  bool // false when the rule's descriptors didn't match, nothing was counted
+	limit    int  // the rule's limit as its algorithm counts it, see ruleLimit
+	res      limiter.Result
+	degraded bool // backend was unavailable, the rule's failure mode decided
+}
+
+// check resolves the rule for one request, counts it and records metrics.
+// CheckLimit and the HTTP endpoint decide through here.
+func (s *RateLimiterServer) check(ctx context.Context, ruleID, clientID string, descriptors store.Descriptors, cost int) (checkOutcome, error) {
+	// Look up the rule
+	rule, err := s.ruleStore.GetRule(ctx, ruleID, clientID)
+	if err != nil {
+		return checkOutcome{}, fmt.Errorf("rule lookup failed: %w", err)
+	}
+	if rule.Matches(descriptors) {
+		if err := s.checkCost(rule, cost); err != nil {
+			return checkOutcome{}, err
+		}
+	}
+	return s.checkRule(ctx, rule, ruleID, clientID, descriptors, cost)
+}
+
+// checkRule is check with the rule already resolved, for callers that look it
+// up their own way
+func (s *RateLimiterServer) checkRule(ctx context.Context, rule store.Rule, ruleID, clientID string, descriptors store.Descriptors, cost int) (checkOutcome, error) {
 	// Start timing the entire request
 	requestStart := time.Now()
 
-	// Look up the rule
-	rule, err := s.ruleStore.GetRule(ctx, req.RuleId)
+	if !rule.Matches(descriptors) {
+		return checkOutcome{rule: rule}, nil
+	}
+
+	l, err := s.limiterFor(rule)
 	if err != nil {
-		return nil, fmt.Errorf("rule lookup failed: %w", err)
+		return checkOutcome{}, err
 	}
 
-	// Build a composite key: rule + client so different rules don't interfere
-	clientKey := fmt.Sprintf("%s:%s", rule.RuleID, req.ClientId)
-	windowSize := time.Duration(rule.WindowSecs) * time.Second
+	// Build a composite key: rule + client (or the rule's key_by descriptors) so different rules don't interfere
+	clientKey := rule.CounterKey(ruleID, descriptors)
 
-	var allowed bool
-	var remaining int
-	
 	// Time the Redis operation specifically
 	redisStart := time.Now()
-
-	switch rule.Algorithm {
-	case "sliding_window":
-		sw := store.NewAtomicSlidingWindow(s.redisClient, rule.Limit, windowSize)
-		allowed, remaining, err = sw.Allow(ctx, clientKey)
-	default: // fixed_window
-		fw := store.NewAtomicFixedWindow(s.redisClient, rule.Limit, windowSize)
-		allowed, remaining, err = fw.Allow(ctx, clientKey)
-	}
+	res, err := s.allow(ctx, l, clientKey, cost)
 
 	// Record Redis latency
 	metrics.RedisDuration.With(prometheus.Labels{
 		"operation": rule.Algorithm,
 	}).Observe(time.Since(redisStart).Seconds())
 
-	if err != nil {
-		return nil, fmt.Errorf("rate limiter error: %w", err)
+	// Backend trouble: the rule's failure mode decides instead
+	degraded := err != nil
+	if degraded {
+		res, err = s.degradedDecision(ctx, rule, clientKey, cost, err)
+		if err != nil {
+			return checkOutcome{}, err
+		}
 	}
 
-	// Record result
+	recordDecision(rule, res.Allowed, requestStart)
+	s.logRequests(requestLog{clientID: clientID, ruleID: ruleID, allowed: res.Allowed})
+
+	return checkOutcome{rule: rule, matched: true, limit: s.ruleLimit(rule), res: res, degraded: degraded}, nil
+}
+
+// recordDecision counts a decision on the rule in the request metrics, timed
+// from requestStart
+func recordDecision(rule store.Rule, allowed bool, requestStart time.Time) {
 	result := "allowed"
 	if !allowed {
 		result = "blocked"
@@ -112,38 +264,41 @@ func (s *RateLimiterServer) CheckLimit(ctx context.Context, req *pb.CheckLimitRe
 		"result":    result,
 	}).Inc()
 
-	// Record total request duration
 	metrics.RequestDuration.With(prometheus.Labels{
 		"rule_id":   rule.RuleID,
 		"algorithm": rule.Algorithm,
 	}).Observe(time.Since(requestStart).Seconds())
+}
 
-	// Log to DB asynchronously
-	go func() {
-		logCtx := context.Background()
-		s.db.Exec(logCtx, `
-			INSERT INTO request_logs (client_id, rule_id, allowed)
-			VALUES ($1, $2, $3)
-		`, req.ClientId, req.RuleId, allowed)
-	}()
+// requestLog is one row of request_logs
+type requestLog struct {
+	clientID string
+	ruleID   string
+	allowed  bool
+}
 
-	retryAfterMs := int64(0)
-	if !allowed {
-		retryAfterMs = windowSize.Milliseconds()
+// logRequests writes decisions to request_logs asynchronously, so the caller
+// never waits on PostgreSQL. Without a database nothing is logged.
+func (s *RateLimiterServer) logRequests(logs ...requestLog) {
+	if s.db == nil || len(logs) == 0 {
+		return
 	}
-
 	go func() {
 		logCtx := context.Background()
-		s.db.Exec(logCtx, `
-			INSERT INTO request_logs (client_id, rule_id, allowed)
-			VALUES ($1, $2, $3)
-		`, req.ClientId, req.RuleId, allowed)
+		for _, l := range logs {
+			s.db.Exec(logCtx, `
+				INSERT INTO request_logs (client_id, rule_id, allowed)
+				VALUES ($1, $2, $3)
+			`, l.clientID, l.ruleID, l.allowed)
+		}
 	}()
+}
 
-	return &pb.CheckLimitResponse{
-		Allowed:      allowed,
-		Remaining:    int32(remaining),
-		RetryAfterMs: retryAfterMs,
-		Algorithm:    rule.Algorithm,
-	}, nil
+// millisCeil rounds a wait up to whole milliseconds so callers never retry a
+// moment too early
+func millisCeil(d time.Duration) int64 {
+	if d <= 0 {
+		return 0
+	}
+	return (d + time.Millisecond - 1).Milliseconds()
 }
\ No newline at end of file
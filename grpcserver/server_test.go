@@ -0,0 +1,57 @@
+package grpcserver
+
+import (
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/cynkin/rlaas/store"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestServer serves rules from memory with counters in a fresh miniredis.
+// Nothing is logged to PostgreSQL.
+func newTestServer(t *testing.T, rules ...store.Rule) (*RateLimiterServer, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	s := NewRateLimiterServer(client, store.NewRedisRegistry(client), store.NewStaticRuleStore(rules...), nil,
+		FailurePolicy{DefaultMode: store.FailClosed})
+	return s, mr
+}
+
+// Buckets that take an hour to refill, so nothing comes back while a test runs
+// and, unlike windows, there is no boundary for it to cross
+var (
+	testBucket = store.Rule{RuleID: "login", Algorithm: "token_bucket", Limit: 3, WindowSecs: 3600}
+	testGCRA   = store.Rule{RuleID: "search", Algorithm: "gcra", Limit: 2, WindowSecs: 3600}
+)
+
+func TestCheckCost(t *testing.T) {
+	tests := []struct {
+		name string
+		rule store.Rule
+		cost int
+		want codes.Code
+	}{
+		{name: "fixed window at the limit", rule: store.Rule{Algorithm: "fixed_window", Limit: 5, WindowSecs: 60}, cost: 5, want: codes.OK},
+		{name: "fixed window above the limit", rule: store.Rule{Algorithm: "fixed_window", Limit: 5, WindowSecs: 60}, cost: 6, want: codes.InvalidArgument},
+		{name: "sliding window above the limit", rule: store.Rule{Algorithm: "sliding_window", Limit: 5, WindowSecs: 60}, cost: 10, want: codes.InvalidArgument},
+		{name: "token bucket within capacity", rule: store.Rule{Algorithm: "token_bucket", Limit: 5, WindowSecs: 60, Capacity: 10}, cost: 10, want: codes.OK},
+		{name: "token bucket above capacity", rule: store.Rule{Algorithm: "token_bucket", Limit: 5, WindowSecs: 60}, cost: 10, want: codes.InvalidArgument},
+		{name: "gcra above burst", rule: store.Rule{Algorithm: "gcra", Limit: 5, WindowSecs: 60}, cost: 10, want: codes.InvalidArgument},
+		// An empty leaky bucket takes any cost and drains it
+		{name: "leaky bucket above capacity", rule: store.Rule{Algorithm: "leaky_bucket", Limit: 5, WindowSecs: 60}, cost: 10, want: codes.OK},
+		{name: "unknown algorithm", rule: store.Rule{Algorithm: "nope", Limit: 5, WindowSecs: 60}, cost: 1, want: codes.Unknown},
+	}
+
+	s := &RateLimiterServer{registry: store.NewRedisRegistry(nil)}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(s.checkCost(tt.rule, tt.cost)); got != tt.want {
+				t.Errorf("checkCost(cost %d) = %v, want %v", tt.cost, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/cynkin/rlaas/metrics"
+	pb "github.com/cynkin/rlaas/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *RateLimiterServer) GetLimitStatus(ctx context.Context, req *pb.GetLimitStatusRequest) (*pb.GetLimitStatusResponse, error) {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	descriptors, err := descriptorsOf(req.ClientId, req.Descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := s.ruleStore.GetRule(ctx, req.RuleId, req.ClientId)
+	if err != nil {
+		return nil, fmt.Errorf("rule lookup failed: %w", err)
+	}
+
+	if !rule.Matches(descriptors) {
+		return &pb.GetLimitStatusResponse{Algorithm: rule.Algorithm}, nil
+	}
+
+	l, err := s.limiterFor(rule)
+	if err != nil {
+		return nil, err
+	}
+	peeker, ok := l.(limiter.Peeker)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "algorithm %q can't report status without consuming", rule.Algorithm)
+	}
+
+	// No failure mode to fall back on here, the counters are simply unknown
+	if health := s.failure.Health; health != nil && !health.Healthy() {
+		return nil, status.Error(codes.Unavailable, "rate limiter backend is unavailable")
+	}
+
+	redisStart := time.Now()
+	res, err := peeker.Peek(ctx, rule.CounterKey(req.RuleId, descriptors))
+	metrics.RedisDuration.With(prometheus.Labels{
+		"operation": "peek",
+	}).Observe(time.Since(redisStart).Seconds())
+
+	if err != nil {
+		return nil, fmt.Errorf("rate limiter error: %w", err)
+	}
+
+	limit := s.ruleLimit(rule)
+	return &pb.GetLimitStatusResponse{
+		Limit:         int32(limit),
+		Used:          int32(max(0, limit-res.Remaining)),
+		Remaining:     int32(res.Remaining),
+		RetryAfterMs:  millisCeil(res.RetryAfter),
+		ResetAtMs:     res.ResetAt.UnixMilli(),
+		Algorithm:     rule.Algorithm,
+		MatchedRuleId: rule.RuleID,
+	}, nil
+}
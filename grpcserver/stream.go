@@ -0,0 +1,74 @@
+package grpcserver
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/cynkin/rlaas/metrics"
+	pb "github.com/cynkin/rlaas/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Checks a stream can have running at once. Past that the stream stops reading
+// until some finish, so one busy caller can't pile up goroutines.
+const maxStreamInFlight = 64
+
+func (s *RateLimiterServer) StreamCheckLimit(stream pb.RateLimiter_StreamCheckLimitServer) error {
+	metrics.ActiveConnections.Inc()
+	defer metrics.ActiveConnections.Dec()
+
+	ctx := stream.Context()
+
+	// Checks answer as soon as they are done, but Send isn't safe to call
+	// from several goroutines at once
+	var sendMu sync.Mutex
+	var wg sync.WaitGroup
+	inFlight := make(chan struct{}, maxStreamInFlight)
+
+	// Nothing may Send once the handler has returned
+	defer wg.Wait()
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+
+			resp := &pb.StreamCheckLimitResponse{CorrelationId: req.CorrelationId}
+
+			// A failed check fails only itself, not the whole stream
+			var err error
+			if req.Check == nil {
+				err = status.Error(codes.InvalidArgument, "check is required")
+			} else {
+				resp.Result, err = s.checkLimit(ctx, req.Check)
+			}
+			if err != nil {
+				st := status.Convert(err)
+				resp.ErrorCode = int32(st.Code())
+				resp.Error = st.Message()
+			}
+
+			// A broken stream shows up on Recv as well, which ends the loop
+			sendMu.Lock()
+			stream.Send(resp)
+			sendMu.Unlock()
+		}()
+	}
+}
@@ -0,0 +1,87 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+
+	pb "github.com/cynkin/rlaas/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// fakeStream feeds StreamCheckLimit its requests and collects what it sends
+type fakeStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs []*pb.StreamCheckLimitRequest
+
+	mu   sync.Mutex
+	sent []*pb.StreamCheckLimitResponse
+}
+
+func (f *fakeStream) Context() context.Context { return f.ctx }
+
+func (f *fakeStream) Recv() (*pb.StreamCheckLimitRequest, error) {
+	if len(f.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := f.reqs[0]
+	f.reqs = f.reqs[1:]
+	return req, nil
+}
+
+func (f *fakeStream) Send(resp *pb.StreamCheckLimitResponse) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, resp)
+	return nil
+}
+
+func TestStreamCheckLimit(t *testing.T) {
+	s, _ := newTestServer(t, testBucket)
+
+	// Checks run concurrently, so only the totals of "acme" are certain
+	stream := &fakeStream{ctx: context.Background(), reqs: []*pb.StreamCheckLimitRequest{
+		{CorrelationId: "a1", Check: &pb.CheckLimitRequest{RuleId: "login", ClientId: "acme", Cost: 2}},
+		{CorrelationId: "a2", Check: &pb.CheckLimitRequest{RuleId: "login", ClientId: "acme", Cost: 2}},
+		{CorrelationId: "b1", Check: &pb.CheckLimitRequest{RuleId: "login", ClientId: "beta"}},
+		{CorrelationId: "nil"},
+		{CorrelationId: "neg", Check: &pb.CheckLimitRequest{RuleId: "login", ClientId: "beta", Cost: -1}},
+		{CorrelationId: "big", Check: &pb.CheckLimitRequest{RuleId: "login", ClientId: "beta", Cost: 4}},
+	}}
+	if err := s.StreamCheckLimit(stream); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]*pb.StreamCheckLimitResponse{}
+	for _, resp := range stream.sent {
+		got[resp.CorrelationId] = resp
+	}
+	if len(stream.sent) != 6 || len(got) != 6 {
+		t.Fatalf("sent %v, want one response per correlation ID", stream.sent)
+	}
+
+	allowed := 0
+	for _, id := range []string{"a1", "a2"} {
+		if resp := got[id]; resp.ErrorCode != 0 || resp.Result == nil {
+			t.Errorf("%s: %v, want a result", id, resp)
+		} else if resp.Result.Allowed {
+			allowed++
+		}
+	}
+	if allowed != 1 {
+		t.Errorf("%d of acme's checks allowed, want 1", allowed)
+	}
+	if resp := got["b1"]; !resp.GetResult().GetAllowed() || resp.Result.Remaining != 2 {
+		t.Errorf("b1: %v, want allowed with 2 remaining", resp)
+	}
+
+	// Failed checks answer with an error and leave the stream running
+	for _, id := range []string{"nil", "neg", "big"} {
+		if resp := got[id]; codes.Code(resp.ErrorCode) != codes.InvalidArgument || resp.Error == "" || resp.Result != nil {
+			t.Errorf("%s: %v, want an InvalidArgument error", id, resp)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package limiter
+
+import "time"
+
+// CalendarWindow returns the start and end of the calendar period containing now,
+// in the given timezone. Weeks start on Monday. Using time.Date keeps boundaries
+// on local midnight across DST changes, where days are 23 or 25 hours long.
+func CalendarWindow(period string, loc *time.Location, now time.Time) (time.Time, time.Time) {
+	t := now.In(loc)
+	year, month, day := t.Date()
+
+	switch period {
+	case "week":
+		offset := (int(t.Weekday()) + 6) % 7 // days since Monday
+		start := time.Date(year, month, day-offset, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 0, 7)
+	case "month":
+		start := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0)
+	default: // day
+		start := time.Date(year, month, day, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 0, 1)
+	}
+}
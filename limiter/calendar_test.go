@@ -0,0 +1,99 @@
+package limiter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarWindow(t *testing.T) {
+	load := func(name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return loc
+	}
+	newYork, berlin := load("America/New_York"), load("Europe/Berlin")
+
+	tests := []struct {
+		name       string
+		period     string
+		loc        *time.Location
+		now        time.Time
+		start, end time.Time
+		length     time.Duration
+	}{
+		{
+			name: "day losing an hour to DST", period: "day", loc: newYork,
+			now:   time.Date(2026, 3, 8, 12, 0, 0, 0, newYork),
+			start: time.Date(2026, 3, 8, 0, 0, 0, 0, newYork), end: time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+			length: 23 * time.Hour,
+		},
+		{
+			name: "day gaining an hour from DST", period: "day", loc: newYork,
+			now:   time.Date(2026, 11, 1, 23, 59, 59, 0, newYork),
+			start: time.Date(2026, 11, 1, 0, 0, 0, 0, newYork), end: time.Date(2026, 11, 2, 0, 0, 0, 0, newYork),
+			length: 25 * time.Hour,
+		},
+		{
+			name: "day in UTC is already the next one locally", period: "day", loc: newYork,
+			now:   time.Date(2026, 3, 9, 2, 0, 0, 0, time.UTC), // 22:00 on the 8th in New York
+			start: time.Date(2026, 3, 8, 0, 0, 0, 0, newYork), end: time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+			length: 23 * time.Hour,
+		},
+		{
+			name: "week across a DST change starts on Monday", period: "week", loc: newYork,
+			now:   time.Date(2026, 3, 8, 12, 0, 0, 0, newYork), // a Sunday
+			start: time.Date(2026, 3, 2, 0, 0, 0, 0, newYork), end: time.Date(2026, 3, 9, 0, 0, 0, 0, newYork),
+			length: 7*24*time.Hour - time.Hour,
+		},
+		{
+			name: "Monday midnight starts a new week", period: "week", loc: berlin,
+			now:   time.Date(2026, 3, 30, 0, 0, 0, 0, berlin),
+			start: time.Date(2026, 3, 30, 0, 0, 0, 0, berlin), end: time.Date(2026, 4, 6, 0, 0, 0, 0, berlin),
+			length: 7 * 24 * time.Hour,
+		},
+		{
+			name: "last moment of a 31 day month", period: "month", loc: time.UTC,
+			now:   time.Date(2026, 1, 31, 23, 59, 59, 999999999, time.UTC),
+			start: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), end: time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+			length: 31 * 24 * time.Hour,
+		},
+		{
+			name: "leap February", period: "month", loc: time.UTC,
+			now:   time.Date(2028, 2, 29, 12, 0, 0, 0, time.UTC),
+			start: time.Date(2028, 2, 1, 0, 0, 0, 0, time.UTC), end: time.Date(2028, 3, 1, 0, 0, 0, 0, time.UTC),
+			length: 29 * 24 * time.Hour,
+		},
+		{
+			name: "month losing an hour to DST", period: "month", loc: berlin,
+			now:   time.Date(2026, 3, 15, 12, 0, 0, 0, berlin),
+			start: time.Date(2026, 3, 1, 0, 0, 0, 0, berlin), end: time.Date(2026, 4, 1, 0, 0, 0, 0, berlin),
+			length: 31*24*time.Hour - time.Hour,
+		},
+		{
+			name: "month in UTC is already the next one locally", period: "month", loc: berlin,
+			now:   time.Date(2026, 3, 31, 22, 30, 0, 0, time.UTC), // 00:30 on April 1st in Berlin
+			start: time.Date(2026, 4, 1, 0, 0, 0, 0, berlin), end: time.Date(2026, 5, 1, 0, 0, 0, 0, berlin),
+			length: 30 * 24 * time.Hour,
+		},
+		{
+			name: "December rolls into the next year", period: "month", loc: newYork,
+			now:   time.Date(2026, 12, 31, 23, 0, 0, 0, newYork),
+			start: time.Date(2026, 12, 1, 0, 0, 0, 0, newYork), end: time.Date(2027, 1, 1, 0, 0, 0, 0, newYork),
+			length: 31 * 24 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := CalendarWindow(tt.period, tt.loc, tt.now)
+			if !start.Equal(tt.start) || !end.Equal(tt.end) {
+				t.Fatalf("window [%v, %v), want [%v, %v)", start, end, tt.start, tt.end)
+			}
+			if got := end.Sub(start); got != tt.length {
+				t.Errorf("window is %v long, want %v", got, tt.length)
+			}
+		})
+	}
+}
@@ -9,12 +9,12 @@ import (
 )
 
 type FixedWindowLimiter struct {
-	client     *redis.Client
+	client     redis.UniversalClient
 	limit      int
 	windowSize time.Duration
 }
 
-func NewFixedWindowLimiter(client *redis.Client, limit int, windowSize time.Duration) *FixedWindowLimiter {
+func NewFixedWindowLimiter(client redis.UniversalClient, limit int, windowSize time.Duration) *FixedWindowLimiter {
 	return &FixedWindowLimiter{
 		client:     client,
 		limit:      limit,
@@ -22,16 +22,17 @@ func NewFixedWindowLimiter(client *redis.Client, limit int, windowSize time.Dura
 	}
 }
 
-func (f *FixedWindowLimiter) Allow(ctx context.Context, clientID string) (bool, int, error) {
-	windowStart := time.Now().Truncate(f.windowSize).Unix()
-	key := fmt.Sprintf("rate:fixed:%s:%d", clientID, windowStart)
+func (f *FixedWindowLimiter) Allow(ctx context.Context, clientID string, cost int) (Result, error) {
+	now := time.Now()
+	windowStart := now.Truncate(f.windowSize)
+	key := fmt.Sprintf("rate:fixed:%s:%d", clientID, windowStart.Unix())
 
-	count, err := f.client.Incr(ctx, key).Result()
+	count, err := f.client.IncrBy(ctx, key, int64(cost)).Result()
 	if err != nil {
-		return false, 0, err
+		return Result{}, err
 	}
 
-	if count == 1 {
+	if count == int64(cost) {
 		f.client.Expire(ctx, key, f.windowSize)
 	}
 
@@ -40,9 +41,10 @@ func (f *FixedWindowLimiter) Allow(ctx context.Context, clientID string) (bool,
 		remaining = 0
 	}
 
+	windowEnd := windowStart.Add(f.windowSize)
 	if int(count) > f.limit {
-		return false, remaining, nil
+		return Result{Allowed: false, Remaining: remaining, RetryAfter: windowEnd.Sub(now), ResetAt: windowEnd}, nil
 	}
 
-	return true, remaining, nil
+	return Result{Allowed: true, Remaining: remaining, ResetAt: windowEnd}, nil
 }
@@ -0,0 +1,172 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Result is the outcome of a single rate limit check
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration // until a request of the same cost would be allowed, 0 when allowed
+	ResetAt    time.Time     // when the client is back to its full allowance
+}
+
+// Limiter is implemented by every rate limiting algorithm, whatever its backend
+type Limiter interface {
+	// Allow consumes cost units for key if they fit and reports the decision
+	Allow(ctx context.Context, key string, cost int) (Result, error)
+}
+
+// Refunder is implemented by limiters that can give back units they consumed.
+// consumedAt tells windowed algorithms which window the units came out of.
+// Refunds are capped so usage never goes below zero, the amount actually
+// given back is returned.
+type Refunder interface {
+	Refund(ctx context.Context, key string, amount int, consumedAt time.Time) (int, error)
+}
+
+// Peeker is implemented by limiters that can report where a key stands without
+// consuming anything. The Result is what a request costing one unit would get
+// right now.
+type Peeker interface {
+	Peek(ctx context.Context, key string) (Result, error)
+}
+
+// Resetter is implemented by limiters that can forget a key's usage, giving it
+// its full allowance back
+type Resetter interface {
+	Reset(ctx context.Context, key string) error
+}
+
+var (
+	_ Limiter = (*FixedWindowLimiter)(nil)
+	_ Limiter = (*SlidingWindowLimiter)(nil)
+	_ Limiter = (*TokenBucketLimiter)(nil)
+)
+
+// Config is everything a rule can tell an algorithm. Each algorithm reads the
+// fields it needs and ignores the rest.
+type Config struct {
+	Limit      int
+	Window     time.Duration
+	Capacity   int            // bucket size or burst, 0 for the algorithm's default
+	RefillRate float64        // refill, drain or emission rate per second
+	Period     string         // calendar period for fixed windows, overrides Window
+	Location   *time.Location // timezone the calendar period follows
+}
+
+// Factory builds a limiter for one rule
+type Factory func(cfg Config) (Limiter, error)
+
+// Algorithm is a named entry in a Registry
+type Algorithm struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	New         Factory `json:"-"` // nil for algorithms not checked through Allow, like in_flight
+
+	DefaultCapacity int                  `json:"-"` // capacity for configs without one, 0 means their limit
+	Allowance       func(cfg Config) int `json:"-"` // most a key can have available, nil means cfg.Limit
+	AnyCost         bool                 `json:"-"` // admits any cost into an empty state, so costs above the allowance still fit
+	LocalFallback   string               `json:"-"` // in-memory algorithm standing in while a shared backend is down, empty means the same name
+}
+
+// CapacityAllowance is the Allowance of algorithms that hold up to their capacity
+func CapacityAllowance(cfg Config) int {
+	return cfg.Capacity
+}
+
+var (
+	// ErrNotRateLimiter is returned by Registry.New for algorithms that are
+	// registered but don't limit request rate
+	ErrNotRateLimiter = errors.New("algorithm does not limit request rate")
+	// ErrCostTooHigh is returned by CheckCost for costs that could never be allowed
+	ErrCostTooHigh = errors.New("cost is more than the limit ever allows")
+)
+
+// Configure fills in the settings cfg leaves to the algorithm's defaults
+func (a Algorithm) Configure(cfg Config) Config {
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = cfg.Limit
+		if a.DefaultCapacity > 0 {
+			cfg.Capacity = a.DefaultCapacity
+		}
+	}
+	return cfg
+}
+
+// Limit is the most a key can have available under cfg, what callers are told
+// the limit is
+func (a Algorithm) Limit(cfg Config) int {
+	cfg = a.Configure(cfg)
+	if a.Allowance != nil {
+		return a.Allowance(cfg)
+	}
+	return cfg.Limit
+}
+
+// CheckCost refuses costs the algorithm could never allow under cfg, which
+// would otherwise be blocked with a retry that never succeeds
+func (a Algorithm) CheckCost(cfg Config, cost int) error {
+	if limit := a.Limit(cfg); !a.AnyCost && cost > limit {
+		return fmt.Errorf("%w: %d > %d", ErrCostTooHigh, cost, limit)
+	}
+	return nil
+}
+
+// Registry maps algorithm names to the factories that build them, so callers
+// pick an algorithm by the name stored on the rule.
+type Registry struct {
+	mu         sync.RWMutex
+	algorithms map[string]Algorithm
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		algorithms: make(map[string]Algorithm),
+	}
+}
+
+// Register adds an algorithm, replacing any existing one with the same name
+func (r *Registry) Register(alg Algorithm) {
+	r.mu.Lock()
+	r.algorithms[alg.Name] = alg
+	r.mu.Unlock()
+}
+
+func (r *Registry) Get(name string) (Algorithm, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	alg, ok := r.algorithms[name]
+	return alg, ok
+}
+
+// List returns every registered algorithm sorted by name
+func (r *Registry) List() []Algorithm {
+	r.mu.RLock()
+	algs := make([]Algorithm, 0, len(r.algorithms))
+	for _, alg := range r.algorithms {
+		algs = append(algs, alg)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(algs, func(i, j int) bool { return algs[i].Name < algs[j].Name })
+	return algs
+}
+
+// New builds the named algorithm's limiter for cfg
+func (r *Registry) New(name string, cfg Config) (Limiter, error) {
+	alg, ok := r.Get(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown algorithm %q", name)
+	}
+	if alg.New == nil {
+		return nil, fmt.Errorf("%s: %w", name, ErrNotRateLimiter)
+	}
+	return alg.New(alg.Configure(cfg))
+}
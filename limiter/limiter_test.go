@@ -0,0 +1,40 @@
+package limiter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAlgorithmLimit(t *testing.T) {
+	window := Algorithm{Name: "window"}
+	bucket := Algorithm{Name: "bucket", Allowance: CapacityAllowance}
+	leaky := Algorithm{Name: "leaky", DefaultCapacity: 1, Allowance: CapacityAllowance, AnyCost: true}
+
+	tests := []struct {
+		name      string
+		alg       Algorithm
+		cfg       Config
+		wantLimit int
+		cost      int
+		wantErr   bool
+	}{
+		{name: "windows allow their limit", alg: window, cfg: Config{Limit: 5, Capacity: 20}, wantLimit: 5, cost: 5},
+		{name: "cost above a window's limit", alg: window, cfg: Config{Limit: 5}, wantLimit: 5, cost: 6, wantErr: true},
+		{name: "capacity defaults to the limit", alg: bucket, cfg: Config{Limit: 5}, wantLimit: 5, cost: 6, wantErr: true},
+		{name: "buckets allow their capacity", alg: bucket, cfg: Config{Limit: 5, Capacity: 20}, wantLimit: 20, cost: 20},
+		{name: "algorithm's own default capacity", alg: leaky, cfg: Config{Limit: 5}, wantLimit: 1, cost: 1},
+		{name: "any cost fits", alg: leaky, cfg: Config{Limit: 5}, wantLimit: 1, cost: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.alg.Limit(tt.cfg); got != tt.wantLimit {
+				t.Errorf("Limit = %d, want %d", got, tt.wantLimit)
+			}
+			err := tt.alg.CheckCost(tt.cfg, tt.cost)
+			if errors.Is(err, ErrCostTooHigh) != tt.wantErr {
+				t.Errorf("CheckCost(%d) = %v, want error %v", tt.cost, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,508 @@
+package limiter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Clock tells the in-memory limiters what time it is, so tests can control it
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the wall clock
+var SystemClock Clock = systemClock{}
+
+// ManualClock only moves when told to, for deterministic tests
+type ManualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// MemoryStore holds counter state for the in-memory limiters, standing in for
+// Redis. Limiters built on the same store share state, and every check runs
+// under one lock, which is what makes the scripts atomic in Redis.
+type MemoryStore struct {
+	mu      sync.Mutex
+	clock   Clock
+	entries map[string]memoryEntry
+	writes  int // sets since the last sweep of expired entries
+}
+
+type memoryEntry struct {
+	value     interface{}
+	expiresAt time.Time
+}
+
+// Sweep expired entries every this many writes so idle clients don't pile up
+const memorySweepEvery = 1000
+
+func NewMemoryStore(clock Clock) *MemoryStore {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &MemoryStore{
+		clock:   clock,
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// get and set must be called with mu held
+
+func (m *MemoryStore) get(key string, now time.Time) (interface{}, bool) {
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !now.Before(e.expiresAt) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+func (m *MemoryStore) set(key string, value interface{}, expiresAt time.Time, now time.Time) {
+	m.entries[key] = memoryEntry{value: value, expiresAt: expiresAt}
+
+	m.writes++
+	if m.writes < memorySweepEvery {
+		return
+	}
+	m.writes = 0
+	for k, e := range m.entries {
+		if !now.Before(e.expiresAt) {
+			delete(m.entries, k)
+		}
+	}
+}
+
+var (
+	_ Refunder = (*MemoryFixedWindowLimiter)(nil)
+	_ Refunder = (*MemorySlidingWindowLimiter)(nil)
+	_ Refunder = (*MemoryTokenBucketLimiter)(nil)
+
+	_ Peeker = (*MemoryFixedWindowLimiter)(nil)
+	_ Peeker = (*MemorySlidingWindowLimiter)(nil)
+	_ Peeker = (*MemoryTokenBucketLimiter)(nil)
+
+	_ Resetter = (*MemoryFixedWindowLimiter)(nil)
+	_ Resetter = (*MemorySlidingWindowLimiter)(nil)
+	_ Resetter = (*MemoryTokenBucketLimiter)(nil)
+)
+
+// The limiters below follow the Lua scripts in store/scripts.go: same decisions,
+// same expiry, same Result maths. Only the storage differs.
+
+type MemoryFixedWindowLimiter struct {
+	store      *MemoryStore
+	limit      int
+	windowSize time.Duration
+	period     string         // calendar period, overrides windowSize when set
+	location   *time.Location // timezone the calendar period is aligned to
+}
+
+func NewMemoryFixedWindow(store *MemoryStore, limit int, windowSize time.Duration) *MemoryFixedWindowLimiter {
+	return &MemoryFixedWindowLimiter{
+		store:      store,
+		limit:      limit,
+		windowSize: windowSize,
+	}
+}
+
+// NewMemoryCalendarWindow is a fixed window that resets on calendar boundaries in loc
+func NewMemoryCalendarWindow(store *MemoryStore, limit int, period string, loc *time.Location) *MemoryFixedWindowLimiter {
+	return &MemoryFixedWindowLimiter{
+		store:    store,
+		limit:    limit,
+		period:   period,
+		location: loc,
+	}
+}
+
+func (f *MemoryFixedWindowLimiter) window(now time.Time) (time.Time, time.Time) {
+	if f.period != "" {
+		return CalendarWindow(f.period, f.location, now)
+	}
+	start := now.Truncate(f.windowSize)
+	return start, start.Add(f.windowSize)
+}
+
+func (f *MemoryFixedWindowLimiter) Allow(ctx context.Context, clientID string, cost int) (Result, error) {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	now := f.store.clock.Now()
+	windowStart, windowEnd := f.window(now)
+	key := fmt.Sprintf("fixed:%s:%d", clientID, windowStart.Unix())
+
+	count := 0
+	if v, ok := f.store.get(key, now); ok {
+		count = v.(int)
+	}
+
+	// Refuse without consuming if this request doesn't fit
+	allowed := count+cost <= f.limit
+	if allowed {
+		count += cost
+		f.store.set(key, count, windowEnd, now)
+	}
+
+	res := Result{Allowed: allowed, Remaining: max(0, f.limit-count), ResetAt: windowEnd}
+	if !allowed {
+		res.RetryAfter = windowEnd.Sub(now)
+	}
+	return res, nil
+}
+
+// Refund gives units back to the window they were consumed in
+func (f *MemoryFixedWindowLimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	now := f.store.clock.Now()
+	windowStart, windowEnd := f.window(consumedAt)
+	key := fmt.Sprintf("fixed:%s:%d", clientID, windowStart.Unix())
+
+	v, ok := f.store.get(key, now)
+	if !ok {
+		return 0, nil
+	}
+	count := v.(int)
+	amount = min(amount, count)
+	if amount <= 0 {
+		return 0, nil
+	}
+	f.store.set(key, count-amount, windowEnd, now)
+	return amount, nil
+}
+
+func (f *MemoryFixedWindowLimiter) Peek(ctx context.Context, clientID string) (Result, error) {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	now := f.store.clock.Now()
+	windowStart, windowEnd := f.window(now)
+	key := fmt.Sprintf("fixed:%s:%d", clientID, windowStart.Unix())
+
+	count := 0
+	if v, ok := f.store.get(key, now); ok {
+		count = v.(int)
+	}
+
+	res := Result{Allowed: count+1 <= f.limit, Remaining: max(0, f.limit-count), ResetAt: now}
+	if count > 0 {
+		res.ResetAt = windowEnd
+	}
+	if !res.Allowed {
+		res.RetryAfter = windowEnd.Sub(now)
+	}
+	return res, nil
+}
+
+// Reset clears the current window, earlier ones no longer count anyway
+func (f *MemoryFixedWindowLimiter) Reset(ctx context.Context, clientID string) error {
+	f.store.mu.Lock()
+	defer f.store.mu.Unlock()
+
+	windowStart, _ := f.window(f.store.clock.Now())
+	delete(f.store.entries, fmt.Sprintf("fixed:%s:%d", clientID, windowStart.Unix()))
+	return nil
+}
+
+type MemorySlidingWindowLimiter struct {
+	store      *MemoryStore
+	limit      int
+	windowSize time.Duration
+}
+
+func NewMemorySlidingWindow(store *MemoryStore, limit int, windowSize time.Duration) *MemorySlidingWindowLimiter {
+	return &MemorySlidingWindowLimiter{
+		store:      store,
+		limit:      limit,
+		windowSize: windowSize,
+	}
+}
+
+func (s *MemorySlidingWindowLimiter) Allow(ctx context.Context, clientID string, cost int) (Result, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	now := s.store.clock.Now()
+	key := "sliding:" + clientID
+
+	// One timestamp per unit, oldest first. Drop the ones outside the window.
+	var entries []time.Time
+	if v, ok := s.store.get(key, now); ok {
+		entries = v.([]time.Time)
+	}
+	windowStart := now.Add(-s.windowSize)
+	for len(entries) > 0 && !entries[0].After(windowStart) {
+		entries = entries[1:]
+	}
+	count := len(entries)
+
+	if count+cost <= s.limit {
+		for i := 0; i < cost; i++ {
+			entries = append(entries, now)
+		}
+		s.store.set(key, entries, now.Add(s.windowSize), now)
+		return Result{Allowed: true, Remaining: s.limit - count - cost, ResetAt: now.Add(s.windowSize)}, nil
+	}
+
+	// Blocked: fits once the oldest (count + cost - limit) entries have left
+	res := Result{Allowed: false, Remaining: max(0, s.limit-count), ResetAt: now, RetryAfter: s.windowSize}
+	if count > 0 {
+		res.ResetAt = entries[count-1].Add(s.windowSize)
+	}
+	if needed := count + cost - s.limit - 1; needed < count {
+		res.RetryAfter = entries[needed].Add(s.windowSize).Sub(now)
+	}
+	return res, nil
+}
+
+// Refund drops the most recent entries first
+func (s *MemorySlidingWindowLimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	now := s.store.clock.Now()
+	key := "sliding:" + clientID
+
+	v, ok := s.store.get(key, now)
+	if !ok {
+		return 0, nil
+	}
+	entries := v.([]time.Time)
+	windowStart := now.Add(-s.windowSize)
+	for len(entries) > 0 && !entries[0].After(windowStart) {
+		entries = entries[1:]
+	}
+
+	amount = min(amount, len(entries))
+	if amount <= 0 {
+		return 0, nil
+	}
+	s.store.set(key, entries[:len(entries)-amount], now.Add(s.windowSize), now)
+	return amount, nil
+}
+
+func (s *MemorySlidingWindowLimiter) Peek(ctx context.Context, clientID string) (Result, error) {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	now := s.store.clock.Now()
+	key := "sliding:" + clientID
+
+	var entries []time.Time
+	if v, ok := s.store.get(key, now); ok {
+		entries = v.([]time.Time)
+	}
+	windowStart := now.Add(-s.windowSize)
+	for len(entries) > 0 && !entries[0].After(windowStart) {
+		entries = entries[1:]
+	}
+	count := len(entries)
+
+	res := Result{Allowed: count+1 <= s.limit, Remaining: max(0, s.limit-count), ResetAt: now}
+	if count > 0 {
+		res.ResetAt = entries[count-1].Add(s.windowSize)
+	}
+	if !res.Allowed {
+		res.RetryAfter = s.windowSize
+		if needed := count - s.limit; needed < count {
+			res.RetryAfter = entries[needed].Add(s.windowSize).Sub(now)
+		}
+	}
+	return res, nil
+}
+
+func (s *MemorySlidingWindowLimiter) Reset(ctx context.Context, clientID string) error {
+	s.store.mu.Lock()
+	defer s.store.mu.Unlock()
+
+	delete(s.store.entries, "sliding:"+clientID)
+	return nil
+}
+
+type MemoryTokenBucketLimiter struct {
+	store      *MemoryStore
+	capacity   int     // max tokens the bucket can hold
+	refillRate float64 // tokens added per second
+	ttl        time.Duration
+}
+
+type memoryBucket struct {
+	tokens     float64
+	lastRefill time.Time
+}
+
+func NewMemoryTokenBucket(store *MemoryStore, capacity int, refillRate float64) *MemoryTokenBucketLimiter {
+	// A missing bucket and a full one are the same thing, see NewAtomicTokenBucket
+	ttl := time.Second
+	if refillRate > 0 {
+		ttl = time.Duration(math.Ceil(float64(capacity)/refillRate)+1) * time.Second
+	}
+
+	return &MemoryTokenBucketLimiter{
+		store:      store,
+		capacity:   capacity,
+		refillRate: refillRate,
+		ttl:        ttl,
+	}
+}
+
+func (t *MemoryTokenBucketLimiter) Allow(ctx context.Context, clientID string, cost int) (Result, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	now := t.store.clock.Now()
+	key := "bucket:" + clientID
+
+	// New clients get a full bucket
+	bucket := memoryBucket{tokens: float64(t.capacity), lastRefill: now}
+	if v, ok := t.store.get(key, now); ok {
+		bucket = v.(memoryBucket)
+	}
+
+	elapsed := math.Max(0, now.Sub(bucket.lastRefill).Seconds())
+	tokens := math.Min(float64(t.capacity), bucket.tokens+elapsed*t.refillRate)
+
+	allowed := tokens >= float64(cost)
+	if allowed {
+		tokens -= float64(cost)
+	}
+	t.store.set(key, memoryBucket{tokens: tokens, lastRefill: now}, now.Add(t.ttl), now)
+
+	// Only whole tokens can be spent, so round down
+	res := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		ResetAt:   now.Add(durationUntil(float64(t.capacity)-tokens, t.refillRate)),
+	}
+	if !allowed {
+		res.RetryAfter = durationUntil(float64(cost)-tokens, t.refillRate)
+	}
+	return res, nil
+}
+
+func (t *MemoryTokenBucketLimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	now := t.store.clock.Now()
+	key := "bucket:" + clientID
+
+	v, ok := t.store.get(key, now)
+	if !ok {
+		return 0, nil // a missing bucket is a full one
+	}
+	bucket := v.(memoryBucket)
+
+	elapsed := math.Max(0, now.Sub(bucket.lastRefill).Seconds())
+	tokens := math.Min(float64(t.capacity), bucket.tokens+elapsed*t.refillRate)
+	refunded := math.Min(float64(t.capacity), tokens+float64(amount)) - tokens
+	if refunded <= 0 {
+		return 0, nil
+	}
+	t.store.set(key, memoryBucket{tokens: tokens + refunded, lastRefill: now}, now.Add(t.ttl), now)
+	return int(math.Round(refunded)), nil
+}
+
+func (t *MemoryTokenBucketLimiter) Peek(ctx context.Context, clientID string) (Result, error) {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	now := t.store.clock.Now()
+	key := "bucket:" + clientID
+
+	tokens := float64(t.capacity)
+	if v, ok := t.store.get(key, now); ok {
+		bucket := v.(memoryBucket)
+		elapsed := math.Max(0, now.Sub(bucket.lastRefill).Seconds())
+		tokens = math.Min(tokens, bucket.tokens+elapsed*t.refillRate)
+	}
+
+	res := Result{
+		Allowed:   tokens >= 1,
+		Remaining: int(math.Floor(tokens)),
+		ResetAt:   now.Add(durationUntil(float64(t.capacity)-tokens, t.refillRate)),
+	}
+	if !res.Allowed {
+		res.RetryAfter = durationUntil(1-tokens, t.refillRate)
+	}
+	return res, nil
+}
+
+// Reset refills the bucket, a missing bucket being a full one
+func (t *MemoryTokenBucketLimiter) Reset(ctx context.Context, clientID string) error {
+	t.store.mu.Lock()
+	defer t.store.mu.Unlock()
+
+	delete(t.store.entries, "bucket:"+clientID)
+	return nil
+}
+
+// durationUntil converts a rate wait (units / units per second) to a duration
+func durationUntil(units float64, rate float64) time.Duration {
+	if units <= 0 || rate <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(units / rate * float64(time.Second)))
+}
+
+// NewMemoryRegistry registers the algorithms the in-memory backend supports.
+// Rules using anything else fail with an unknown algorithm error.
+func NewMemoryRegistry(store *MemoryStore) *Registry {
+	reg := NewRegistry()
+
+	reg.Register(Algorithm{
+		Name:        "fixed_window",
+		Description: "Counter that resets every window_secs, or on calendar boundaries when period is set",
+		New: func(cfg Config) (Limiter, error) {
+			if cfg.Period != "" {
+				return NewMemoryCalendarWindow(store, cfg.Limit, cfg.Period, cfg.Location), nil
+			}
+			return NewMemoryFixedWindow(store, cfg.Limit, cfg.Window), nil
+		},
+	})
+	reg.Register(Algorithm{
+		Name:        "sliding_window",
+		Description: "Exact log of request timestamps over the last window_secs",
+		New: func(cfg Config) (Limiter, error) {
+			return NewMemorySlidingWindow(store, cfg.Limit, cfg.Window), nil
+		},
+	})
+	reg.Register(Algorithm{
+		Name:        "token_bucket",
+		Description: "Bucket of capacity tokens refilled at refill_rate per second, allows bursts",
+		New: func(cfg Config) (Limiter, error) {
+			return NewMemoryTokenBucket(store, cfg.Capacity, cfg.RefillRate), nil
+		},
+		Allowance: CapacityAllowance,
+	})
+
+	return reg
+}
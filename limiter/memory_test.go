@@ -0,0 +1,326 @@
+package limiter
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Minute aligned, so fixed windows start with the test
+var testStart = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// step is one check against a limiter, after moving the clock on by advance
+type step struct {
+	advance    time.Duration
+	peek       bool // Peek instead of Allow, cost is ignored
+	cost       int
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+	resetIn    time.Duration // ResetAt relative to the clock at the step
+}
+
+func runSteps(t *testing.T, clock *ManualClock, l Limiter, steps []step) {
+	t.Helper()
+	ctx := context.Background()
+
+	for i, s := range steps {
+		clock.Advance(s.advance)
+
+		var res Result
+		var err error
+		if s.peek {
+			res, err = l.(Peeker).Peek(ctx, "client")
+		} else {
+			res, err = l.Allow(ctx, "client", s.cost)
+		}
+		if err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+
+		want := Result{
+			Allowed:    s.allowed,
+			Remaining:  s.remaining,
+			RetryAfter: s.retryAfter,
+			ResetAt:    clock.Now().Add(s.resetIn),
+		}
+		if res != want {
+			t.Errorf("step %d: got %+v, want %+v", i, res, want)
+		}
+	}
+}
+
+func TestMemoryFixedWindow(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		steps []step
+	}{
+		{
+			name:  "blocks until the window rolls over",
+			limit: 3,
+			steps: []step{
+				{cost: 1, allowed: true, remaining: 2, resetIn: time.Minute},
+				{advance: 10 * time.Second, cost: 2, allowed: true, remaining: 0, resetIn: 50 * time.Second},
+				{advance: 10 * time.Second, cost: 1, allowed: false, remaining: 0, retryAfter: 40 * time.Second, resetIn: 40 * time.Second},
+				{advance: 40 * time.Second, cost: 3, allowed: true, remaining: 0, resetIn: time.Minute},
+			},
+		},
+		{
+			name:  "a cost that doesn't fit consumes nothing",
+			limit: 5,
+			steps: []step{
+				{cost: 3, allowed: true, remaining: 2, resetIn: time.Minute},
+				{cost: 3, allowed: false, remaining: 2, retryAfter: time.Minute, resetIn: time.Minute},
+				{cost: 2, allowed: true, remaining: 0, resetIn: time.Minute},
+			},
+		},
+		{
+			// Costs above the limit are refused before they reach a limiter
+			name:  "cost of the whole limit fits",
+			limit: 2,
+			steps: []step{
+				{cost: 2, allowed: true, remaining: 0, resetIn: time.Minute},
+			},
+		},
+		{
+			name:  "peek doesn't consume",
+			limit: 2,
+			steps: []step{
+				{peek: true, allowed: true, remaining: 2},
+				{peek: true, allowed: true, remaining: 2},
+				{cost: 2, allowed: true, remaining: 0, resetIn: time.Minute},
+				{advance: 15 * time.Second, peek: true, allowed: false, remaining: 0, retryAfter: 45 * time.Second, resetIn: 45 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(testStart)
+			l := NewMemoryFixedWindow(NewMemoryStore(clock), tt.limit, time.Minute)
+			runSteps(t, clock, l, tt.steps)
+		})
+	}
+}
+
+func TestMemorySlidingWindow(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		steps []step
+	}{
+		{
+			name:  "units leave one by one",
+			limit: 3,
+			steps: []step{
+				{cost: 1, allowed: true, remaining: 2, resetIn: time.Minute},
+				{advance: 20 * time.Second, cost: 2, allowed: true, remaining: 0, resetIn: time.Minute},
+				// Room for one once the unit from 0s has left
+				{advance: 10 * time.Second, cost: 1, allowed: false, remaining: 0, retryAfter: 30 * time.Second, resetIn: 50 * time.Second},
+				// Room for two once the first unit from 20s has left as well
+				{advance: 10 * time.Second, cost: 2, allowed: false, remaining: 0, retryAfter: 40 * time.Second, resetIn: 40 * time.Second},
+				{peek: true, allowed: false, remaining: 0, retryAfter: 20 * time.Second, resetIn: 40 * time.Second},
+				{advance: 20 * time.Second, cost: 1, allowed: true, remaining: 0, resetIn: time.Minute},
+				{advance: 20 * time.Second, cost: 2, allowed: true, remaining: 0, resetIn: time.Minute},
+			},
+		},
+		{
+			name:  "cost of the whole limit fits",
+			limit: 2,
+			steps: []step{
+				{cost: 2, allowed: true, remaining: 0, resetIn: time.Minute},
+			},
+		},
+		{
+			name:  "peek doesn't consume",
+			limit: 2,
+			steps: []step{
+				{peek: true, allowed: true, remaining: 2},
+				{cost: 1, allowed: true, remaining: 1, resetIn: time.Minute},
+				{advance: 30 * time.Second, peek: true, allowed: true, remaining: 1, resetIn: 30 * time.Second},
+				{peek: true, allowed: true, remaining: 1, resetIn: 30 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(testStart)
+			l := NewMemorySlidingWindow(NewMemoryStore(clock), tt.limit, time.Minute)
+			runSteps(t, clock, l, tt.steps)
+		})
+	}
+}
+
+func TestMemoryTokenBucket(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "refills at the rate",
+			steps: []step{
+				{cost: 4, allowed: true, remaining: 0, resetIn: 4 * time.Second},
+				{cost: 1, allowed: false, remaining: 0, retryAfter: time.Second, resetIn: 4 * time.Second},
+				{advance: 500 * time.Millisecond, cost: 1, allowed: false, remaining: 0, retryAfter: 500 * time.Millisecond, resetIn: 3500 * time.Millisecond},
+				{advance: 1500 * time.Millisecond, cost: 2, allowed: true, remaining: 0, resetIn: 4 * time.Second},
+			},
+		},
+		{
+			name: "refill stops at capacity",
+			steps: []step{
+				{cost: 1, allowed: true, remaining: 3, resetIn: time.Second},
+				{advance: 10 * time.Second, peek: true, allowed: true, remaining: 4},
+				{cost: 4, allowed: true, remaining: 0, resetIn: 4 * time.Second},
+			},
+		},
+		{
+			name: "cost of the whole capacity fits",
+			steps: []step{
+				{cost: 4, allowed: true, remaining: 0, resetIn: 4 * time.Second},
+			},
+		},
+		{
+			name: "peek doesn't consume",
+			steps: []step{
+				{cost: 3, allowed: true, remaining: 1, resetIn: 3 * time.Second},
+				{peek: true, allowed: true, remaining: 1, resetIn: 3 * time.Second},
+				{peek: true, allowed: true, remaining: 1, resetIn: 3 * time.Second},
+				{cost: 1, allowed: true, remaining: 0, resetIn: 4 * time.Second},
+				{peek: true, allowed: false, remaining: 0, retryAfter: time.Second, resetIn: 4 * time.Second},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(testStart)
+			l := NewMemoryTokenBucket(NewMemoryStore(clock), 4, 1)
+			runSteps(t, clock, l, tt.steps)
+		})
+	}
+}
+
+func TestMemoryRefund(t *testing.T) {
+	type limiter interface {
+		Limiter
+		Refunder
+	}
+
+	tests := []struct {
+		name          string
+		new           func(*MemoryStore) limiter
+		consume       int
+		advance       time.Duration // between consuming and refunding
+		refund        int
+		refunded      int
+		wantRemaining int
+	}{
+		{
+			name:          "fixed window",
+			new:           func(s *MemoryStore) limiter { return NewMemoryFixedWindow(s, 5, time.Minute) },
+			consume:       3,
+			refund:        2,
+			refunded:      2,
+			wantRemaining: 4,
+		},
+		{
+			name:          "fixed window capped at zero usage",
+			new:           func(s *MemoryStore) limiter { return NewMemoryFixedWindow(s, 5, time.Minute) },
+			consume:       3,
+			refund:        10,
+			refunded:      3,
+			wantRemaining: 5,
+		},
+		{
+			name:          "fixed window that has already ended",
+			new:           func(s *MemoryStore) limiter { return NewMemoryFixedWindow(s, 5, time.Minute) },
+			consume:       3,
+			advance:       time.Minute,
+			refund:        3,
+			refunded:      0,
+			wantRemaining: 5,
+		},
+		{
+			name:          "sliding window capped at zero usage",
+			new:           func(s *MemoryStore) limiter { return NewMemorySlidingWindow(s, 5, time.Minute) },
+			consume:       3,
+			refund:        10,
+			refunded:      3,
+			wantRemaining: 5,
+		},
+		{
+			name:          "sliding window skips units that already left",
+			new:           func(s *MemoryStore) limiter { return NewMemorySlidingWindow(s, 5, time.Minute) },
+			consume:       3,
+			advance:       time.Minute,
+			refund:        3,
+			refunded:      0,
+			wantRemaining: 5,
+		},
+		{
+			name:          "token bucket capped at capacity",
+			new:           func(s *MemoryStore) limiter { return NewMemoryTokenBucket(s, 4, 1) },
+			consume:       3,
+			advance:       time.Second,
+			refund:        5,
+			refunded:      2,
+			wantRemaining: 4,
+		},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := NewManualClock(testStart)
+			l := tt.new(NewMemoryStore(clock))
+
+			consumedAt := clock.Now()
+			if res, err := l.Allow(ctx, "client", tt.consume); err != nil || !res.Allowed {
+				t.Fatalf("consume: %+v, %v", res, err)
+			}
+			clock.Advance(tt.advance)
+
+			refunded, err := l.Refund(ctx, "client", tt.refund, consumedAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refunded != tt.refunded {
+				t.Errorf("refunded %d, want %d", refunded, tt.refunded)
+			}
+
+			// Exactly what is left fits, nothing more
+			if res, err := l.Allow(ctx, "client", tt.wantRemaining); err != nil || !res.Allowed || res.Remaining != 0 {
+				t.Errorf("%d not left after refund: %+v, %v", tt.wantRemaining, res, err)
+			}
+		})
+	}
+}
+
+func TestMemoryReset(t *testing.T) {
+	clock := NewManualClock(testStart)
+	store := NewMemoryStore(clock)
+	ctx := context.Background()
+
+	limiters := map[string]interface {
+		Limiter
+		Resetter
+	}{
+		"fixed_window":   NewMemoryFixedWindow(store, 2, time.Minute),
+		"sliding_window": NewMemorySlidingWindow(store, 2, time.Minute),
+		"token_bucket":   NewMemoryTokenBucket(store, 2, 1),
+	}
+
+	for name, l := range limiters {
+		t.Run(name, func(t *testing.T) {
+			l.Allow(ctx, "client", 2)
+			if err := l.Reset(ctx, "client"); err != nil {
+				t.Fatal(err)
+			}
+			if res, _ := l.Allow(ctx, "client", 2); !res.Allowed {
+				t.Errorf("full allowance not back after reset: %+v", res)
+			}
+		})
+	}
+}
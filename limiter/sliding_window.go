@@ -9,12 +9,12 @@ import (
 )
 
 type SlidingWindowLimiter struct {
-	client     *redis.Client
+	client     redis.UniversalClient
 	limit      int
 	windowSize time.Duration
 }
 
-func NewSlidingWindowLimiter(client *redis.Client, limit int, windowSize time.Duration) *SlidingWindowLimiter {
+func NewSlidingWindowLimiter(client redis.UniversalClient, limit int, windowSize time.Duration) *SlidingWindowLimiter {
 	return &SlidingWindowLimiter{
 		client:     client,
 		limit:      limit,
@@ -22,7 +22,7 @@ func NewSlidingWindowLimiter(client *redis.Client, limit int, windowSize time.Du
 	}
 }
 
-func (s *SlidingWindowLimiter) Allow(ctx context.Context, clientID string) (bool, int, error) {
+func (s *SlidingWindowLimiter) Allow(ctx context.Context, clientID string, cost int) (Result, error) {
 	key := fmt.Sprintf("rate:sliding:%s", clientID)
 	now := time.Now().UnixMicro()
 	windowStart := now - s.windowSize.Microseconds()
@@ -33,27 +33,30 @@ func (s *SlidingWindowLimiter) Allow(ctx context.Context, clientID string) (bool
 	countCmd := pipe.ZCard(ctx, key)
 
 	if _, err := pipe.Exec(ctx); err != nil {
-		return false, 0, err
+		return Result{}, err
 	}
 
 	count := int(countCmd.Val())
+	resetAt := time.UnixMicro(now).Add(s.windowSize)
 
-	if count >= s.limit {
-		return false, 0, nil
+	if count+cost > s.limit {
+		return Result{Allowed: false, Remaining: 0, RetryAfter: s.windowSize, ResetAt: resetAt}, nil
 	}
 
 	pipe2 := s.client.TxPipeline()
 
-	pipe2.ZAdd(ctx, key, redis.Z{
-		Score:  float64(now),
-		Member: now,
-	})
+	for i := 0; i < cost; i++ {
+		pipe2.ZAdd(ctx, key, redis.Z{
+			Score:  float64(now),
+			Member: fmt.Sprintf("%d:%d", now, i),
+		})
+	}
 
 	pipe2.Expire(ctx, key, s.windowSize)
 
 	if _, err := pipe2.Exec(ctx); err != nil {
-		return false, 0, err
+		return Result{}, err
 	}
 
-	return true, s.limit - count - 1, nil
-}
\ No newline at end of file
+	return Result{Allowed: true, Remaining: s.limit - count - cost, ResetAt: resetAt}, nil
+}
@@ -11,13 +11,13 @@ import (
 )
 
 type TokenBucketLimiter struct {
-	client       *redis.Client
+	client       redis.UniversalClient
 	capacity     float64       // max tokens the bucket can hold
 	refillRate   float64       // tokens added per second
 	windowSize   time.Duration // TTL for the Redis key
 }
 
-func NewTokenBucketLimiter(client *redis.Client, capacity float64, refillRate float64) *TokenBucketLimiter {
+func NewTokenBucketLimiter(client redis.UniversalClient, capacity float64, refillRate float64) *TokenBucketLimiter {
 	return &TokenBucketLimiter{
 		client:     client,
 		capacity:   capacity,
@@ -26,7 +26,7 @@ func NewTokenBucketLimiter(client *redis.Client, capacity float64, refillRate fl
 	}
 }
 
-func (t *TokenBucketLimiter) Allow(ctx context.Context, clientID string) (bool, float64, error) {
+func (t *TokenBucketLimiter) Allow(ctx context.Context, clientID string, cost int) (Result, error) {
 	tokensKey := fmt.Sprintf("rate:bucket:%s:tokens", clientID)
 	lastRefillKey := fmt.Sprintf("rate:bucket:%s:last_refill", clientID)
 
@@ -42,7 +42,7 @@ func (t *TokenBucketLimiter) Allow(ctx context.Context, clientID string) (bool,
 	if err == redis.Nil {
 		currentTokens = t.capacity // new client gets a full bucket
 	} else if err != nil {
-		return false, 0, fmt.Errorf("redis error: %w", err)
+		return Result{}, fmt.Errorf("redis error: %w", err)
 	} else {
 		currentTokens, _ = strconv.ParseFloat(tokensStr, 64)
 	}
@@ -53,7 +53,7 @@ func (t *TokenBucketLimiter) Allow(ctx context.Context, clientID string) (bool,
 	if err == redis.Nil {
 		lastRefill = now
 	} else if err != nil {
-		return false, 0, fmt.Errorf("redis error: %w", err)
+		return Result{}, fmt.Errorf("redis error: %w", err)
 	} else {
 		lastRefill, _ = strconv.ParseInt(lastRefillStr, 10, 64)
 	}
@@ -62,9 +62,9 @@ func (t *TokenBucketLimiter) Allow(ctx context.Context, clientID string) (bool,
 	tokensToAdd := elapsedSeconds * t.refillRate
 	currentTokens = math.Min(t.capacity, currentTokens+tokensToAdd)
 
-	allowed := currentTokens >= 1.0
+	allowed := currentTokens >= float64(cost)
 	if allowed {
-		currentTokens -= 1.0
+		currentTokens -= float64(cost)
 	}
 
 	pipe2 := t.client.Pipeline()
@@ -72,5 +72,14 @@ func (t *TokenBucketLimiter) Allow(ctx context.Context, clientID string) (bool,
 	pipe2.Set(ctx, lastRefillKey, strconv.FormatInt(now, 10), t.windowSize)
 	pipe2.Exec(ctx)
 
-	return allowed, currentTokens, nil
+	res := Result{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(currentTokens)), // only whole tokens can be spent
+		ResetAt:   time.UnixMicro(now).Add(time.Duration((t.capacity - currentTokens) / t.refillRate * float64(time.Second))),
+	}
+	if !allowed {
+		res.RetryAfter = time.Duration((float64(cost) - currentTokens) / t.refillRate * float64(time.Second))
+	}
+
+	return res, nil
 }
\ No newline at end of file
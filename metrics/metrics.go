@@ -51,4 +51,13 @@ var (
 		},
 		[]string{"result"}, // result = "hit" or "miss"
 	)
-)
\ No newline at end of file
+
+	// Decisions made without the backend because it errored
+	DegradedDecisionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "rlaas_degraded_decisions_total",
+			Help: "Rate limit decisions made by a rule's failure mode after a backend error",
+		},
+		[]string{"rule_id", "failure_mode", "result"}, // failure_mode = "open", "closed" or "local-fallback"
+	)
+)
@@ -4,21 +4,36 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 func runMigrations(ctx context.Context, db *pgxpool.Pool) error {
-	sql, err := os.ReadFile("migrations/001_init.sql")
+	// Files are numbered (001_init.sql, 002_...) so lexical order is apply order.
+	// Every migration must be safe to re-run on each startup.
+	files, err := filepath.Glob("migrations/*.sql")
 	if err != nil {
-		return fmt.Errorf("could not read migration file: %w", err)
+		return fmt.Errorf("could not list migration files: %w", err)
 	}
+	if len(files) == 0 {
+		return fmt.Errorf("no migration files found in migrations/")
+	}
+	sort.Strings(files)
 
-	_, err = db.Exec(ctx, string(sql))
-	if err != nil {
-		return fmt.Errorf("migration failed: %w", err)
+	for _, file := range files {
+		sql, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("could not read migration file %s: %w", file, err)
+		}
+
+		_, err = db.Exec(ctx, string(sql))
+		if err != nil {
+			return fmt.Errorf("migration %s failed: %w", file, err)
+		}
 	}
 
-	fmt.Println("✓ Migrations applied")
+	fmt.Printf("✓ Migrations applied (%d files)\n", len(files))
 	return nil
-}
\ No newline at end of file
+}
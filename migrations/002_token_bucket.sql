@@ -0,0 +1,4 @@
+-- Token bucket parameters. NULL means "derive from limit / window_secs":
+-- capacity defaults to limit, refill_rate defaults to limit / window_secs.
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS capacity    INTEGER;
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS refill_rate DOUBLE PRECISION;
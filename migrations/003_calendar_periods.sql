@@ -0,0 +1,4 @@
+-- Calendar-aligned fixed windows. NULL period means the rule uses window_secs;
+-- NULL timezone means UTC.
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS period   VARCHAR(10);
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS timezone VARCHAR(64);
@@ -0,0 +1,3 @@
+-- What CheckLimit does when the backend errors: 'open', 'closed' or
+-- 'local-fallback'. NULL means the server default (FAILURE_MODE).
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS failure_mode VARCHAR(20);
@@ -0,0 +1,5 @@
+-- Per-client overrides: a rule_id can now have one generic row (client_id NULL)
+-- plus one row per client that gets different limits.
+ALTER TABLE rules DROP CONSTRAINT IF EXISTS rules_rule_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_rules_rule_client
+    ON rules (rule_id, COALESCE(client_id, ''));
@@ -0,0 +1,5 @@
+-- Descriptor matching: match_descriptors maps descriptor keys to the value a
+-- check must carry ('*' for any value, '' for absent), key_by lists the
+-- descriptors counters are kept per. NULL means match everything, per client.
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS match_descriptors JSONB;
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS key_by TEXT[];
@@ -25,6 +25,8 @@ type CheckLimitRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"` // who is making the request
 	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`       // which rule to apply (e.g. "login", "search")
+	Cost          int32                  `protobuf:"varint,3,opt,name=cost,proto3" json:"cost,omitempty"`                        // units this request consumes, defaults to 1
+	Descriptors   []*Descriptor          `protobuf:"bytes,4,rep,name=descriptors,proto3" json:"descriptors,omitempty"`           // what the request is (ip, path, method...), for rules that match or key on them
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -73,19 +75,90 @@ func (x *CheckLimitRequest) GetRuleId() string {
 	return ""
 }
 
+func (x *CheckLimitRequest) GetCost() int32 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+func (x *CheckLimitRequest) GetDescriptors() []*Descriptor {
+	if x != nil {
+		return x.Descriptors
+	}
+	return nil
+}
+
+// A fact about the request a rule can match or key its counters on, like
+// {key: "ip", value: "10.0.0.1"} or {key: "method", value: "POST"}
+type Descriptor struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Descriptor) Reset() {
+	*x = Descriptor{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Descriptor) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Descriptor) ProtoMessage() {}
+
+func (x *Descriptor) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Descriptor.ProtoReflect.Descriptor instead.
+func (*Descriptor) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Descriptor) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *Descriptor) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
 type CheckLimitResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Allowed       bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`                                 // should the caller proceed?
-	Remaining     int32                  `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`                             // how many requests left in window
-	RetryAfterMs  int64                  `protobuf:"varint,3,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"` // if blocked, wait this long before retrying
-	Algorithm     string                 `protobuf:"bytes,4,opt,name=algorithm,proto3" json:"algorithm,omitempty"`                              // which algorithm handled this (for observability)
+	Allowed       bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`                                   // should the caller proceed?
+	Remaining     int32                  `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`                               // how many requests left in window
+	RetryAfterMs  int64                  `protobuf:"varint,3,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"`   // if blocked, wait this long before retrying
+	Algorithm     string                 `protobuf:"bytes,4,opt,name=algorithm,proto3" json:"algorithm,omitempty"`                                // which algorithm handled this (for observability)
+	ResetAtMs     int64                  `protobuf:"varint,5,opt,name=reset_at_ms,json=resetAtMs,proto3" json:"reset_at_ms,omitempty"`            // unix millis when the client is back to its full allowance
+	Degraded      bool                   `protobuf:"varint,6,opt,name=degraded,proto3" json:"degraded,omitempty"`                                 // backend was unavailable, decided by the rule's failure mode
+	MatchedRuleId string                 `protobuf:"bytes,7,opt,name=matched_rule_id,json=matchedRuleId,proto3" json:"matched_rule_id,omitempty"` // rule that applied: rule_id itself, a pattern like "api.v1.*", or "default"; empty when the rule's descriptors didn't match
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CheckLimitResponse) Reset() {
 	*x = CheckLimitResponse{}
-	mi := &file_proto_ratelimiter_proto_msgTypes[1]
+	mi := &file_proto_ratelimiter_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -97,7 +170,7 @@ func (x *CheckLimitResponse) String() string {
 func (*CheckLimitResponse) ProtoMessage() {}
 
 func (x *CheckLimitResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_ratelimiter_proto_msgTypes[1]
+	mi := &file_proto_ratelimiter_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -110,7 +183,7 @@ func (x *CheckLimitResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CheckLimitResponse.ProtoReflect.Descriptor instead.
 func (*CheckLimitResponse) Descriptor() ([]byte, []int) {
-	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{1}
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *CheckLimitResponse) GetAllowed() bool {
@@ -141,22 +214,1474 @@ func (x *CheckLimitResponse) GetAlgorithm() string {
 	return ""
 }
 
-var File_proto_ratelimiter_proto protoreflect.FileDescriptor
+func (x *CheckLimitResponse) GetResetAtMs() int64 {
+	if x != nil {
+		return x.ResetAtMs
+	}
+	return 0
+}
 
-const file_proto_ratelimiter_proto_rawDesc = "" +
-	"\n" +
-	"\x17proto/ratelimiter.proto\x12\vratelimiter\"I\n" +
-	"\x11CheckLimitRequest\x12\x1b\n" +
-	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x17\n" +
-	"\arule_id\x18\x02 \x01(\tR\x06ruleId\"\x90\x01\n" +
-	"\x12CheckLimitResponse\x12\x18\n" +
-	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x1c\n" +
-	"\tremaining\x18\x02 \x01(\x05R\tremaining\x12$\n" +
-	"\x0eretry_after_ms\x18\x03 \x01(\x03R\fretryAfterMs\x12\x1c\n" +
-	"\talgorithm\x18\x04 \x01(\tR\talgorithm2\\\n" +
-	"\vRateLimiter\x12M\n" +
+func (x *CheckLimitResponse) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+func (x *CheckLimitResponse) GetMatchedRuleId() string {
+	if x != nil {
+		return x.MatchedRuleId
+	}
+	return ""
+}
+
+type CheckLimitsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	RuleIds       []string               `protobuf:"bytes,2,rep,name=rule_ids,json=ruleIds,proto3" json:"rule_ids,omitempty"` // every rule must allow for the request to pass
+	Cost          int32                  `protobuf:"varint,3,opt,name=cost,proto3" json:"cost,omitempty"`                     // units consumed from each rule, defaults to 1
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckLimitsRequest) Reset() {
+	*x = CheckLimitsRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckLimitsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckLimitsRequest) ProtoMessage() {}
+
+func (x *CheckLimitsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckLimitsRequest.ProtoReflect.Descriptor instead.
+func (*CheckLimitsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CheckLimitsRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *CheckLimitsRequest) GetRuleIds() []string {
+	if x != nil {
+		return x.RuleIds
+	}
+	return nil
+}
+
+func (x *CheckLimitsRequest) GetCost() int32 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+type RuleResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Allowed       bool                   `protobuf:"varint,2,opt,name=allowed,proto3" json:"allowed,omitempty"` // would this rule alone have allowed the request?
+	Remaining     int32                  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	RetryAfterMs  int64                  `protobuf:"varint,4,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"`
+	Algorithm     string                 `protobuf:"bytes,5,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	ResetAtMs     int64                  `protobuf:"varint,6,opt,name=reset_at_ms,json=resetAtMs,proto3" json:"reset_at_ms,omitempty"`
+	Degraded      bool                   `protobuf:"varint,7,opt,name=degraded,proto3" json:"degraded,omitempty"` // backend was unavailable, decided by the rule's failure mode
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RuleResult) Reset() {
+	*x = RuleResult{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RuleResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RuleResult) ProtoMessage() {}
+
+func (x *RuleResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RuleResult.ProtoReflect.Descriptor instead.
+func (*RuleResult) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RuleResult) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *RuleResult) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *RuleResult) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *RuleResult) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *RuleResult) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *RuleResult) GetResetAtMs() int64 {
+	if x != nil {
+		return x.ResetAtMs
+	}
+	return 0
+}
+
+func (x *RuleResult) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+type CheckLimitsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Allowed       bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`                                 // true only if every rule allowed
+	RetryAfterMs  int64                  `protobuf:"varint,2,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"` // longest wait among the blocking rules
+	Results       []*RuleResult          `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`                                  // one per requested rule, same order
+	Degraded      bool                   `protobuf:"varint,4,opt,name=degraded,proto3" json:"degraded,omitempty"`                               // backend was unavailable, each rule's failure mode decided
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckLimitsResponse) Reset() {
+	*x = CheckLimitsResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckLimitsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckLimitsResponse) ProtoMessage() {}
+
+func (x *CheckLimitsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckLimitsResponse.ProtoReflect.Descriptor instead.
+func (*CheckLimitsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CheckLimitsResponse) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *CheckLimitsResponse) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *CheckLimitsResponse) GetResults() []*RuleResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *CheckLimitsResponse) GetDegraded() bool {
+	if x != nil {
+		return x.Degraded
+	}
+	return false
+}
+
+type BatchCheckLimitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*CheckLimitRequest   `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"` // checked like CheckLimit, descriptors included
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckLimitRequest) Reset() {
+	*x = BatchCheckLimitRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckLimitRequest) ProtoMessage() {}
+
+func (x *BatchCheckLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckLimitRequest.ProtoReflect.Descriptor instead.
+func (*BatchCheckLimitRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *BatchCheckLimitRequest) GetItems() []*CheckLimitRequest {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type BatchCheckLimitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*CheckLimitResponse  `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"` // one per item, same order
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BatchCheckLimitResponse) Reset() {
+	*x = BatchCheckLimitResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BatchCheckLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchCheckLimitResponse) ProtoMessage() {}
+
+func (x *BatchCheckLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchCheckLimitResponse.ProtoReflect.Descriptor instead.
+func (*BatchCheckLimitResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BatchCheckLimitResponse) GetResults() []*CheckLimitResponse {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type StreamCheckLimitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"` // chosen by the caller, echoed on the decision
+	Check         *CheckLimitRequest     `protobuf:"bytes,2,opt,name=check,proto3" json:"check,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamCheckLimitRequest) Reset() {
+	*x = StreamCheckLimitRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamCheckLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamCheckLimitRequest) ProtoMessage() {}
+
+func (x *StreamCheckLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamCheckLimitRequest.ProtoReflect.Descriptor instead.
+func (*StreamCheckLimitRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamCheckLimitRequest) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *StreamCheckLimitRequest) GetCheck() *CheckLimitRequest {
+	if x != nil {
+		return x.Check
+	}
+	return nil
+}
+
+type StreamCheckLimitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	Result        *CheckLimitResponse    `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`                         // unset when the check failed
+	ErrorCode     int32                  `protobuf:"varint,3,opt,name=error_code,json=errorCode,proto3" json:"error_code,omitempty"` // gRPC status code of the failure, the stream stays open
+	Error         string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamCheckLimitResponse) Reset() {
+	*x = StreamCheckLimitResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamCheckLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamCheckLimitResponse) ProtoMessage() {}
+
+func (x *StreamCheckLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamCheckLimitResponse.ProtoReflect.Descriptor instead.
+func (*StreamCheckLimitResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamCheckLimitResponse) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *StreamCheckLimitResponse) GetResult() *CheckLimitResponse {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *StreamCheckLimitResponse) GetErrorCode() int32 {
+	if x != nil {
+		return x.ErrorCode
+	}
+	return 0
+}
+
+func (x *StreamCheckLimitResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetLimitStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Descriptors   []*Descriptor          `protobuf:"bytes,3,rep,name=descriptors,proto3" json:"descriptors,omitempty"` // same as CheckLimit, for rules that match or key on them
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLimitStatusRequest) Reset() {
+	*x = GetLimitStatusRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLimitStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLimitStatusRequest) ProtoMessage() {}
+
+func (x *GetLimitStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLimitStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetLimitStatusRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetLimitStatusRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *GetLimitStatusRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *GetLimitStatusRequest) GetDescriptors() []*Descriptor {
+	if x != nil {
+		return x.Descriptors
+	}
+	return nil
+}
+
+type GetLimitStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"` // most the client can have available: the limit, or capacity for bucket algorithms
+	Used          int32                  `protobuf:"varint,2,opt,name=used,proto3" json:"used,omitempty"`
+	Remaining     int32                  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	RetryAfterMs  int64                  `protobuf:"varint,4,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"` // until one more unit would be allowed, 0 if it would be now
+	ResetAtMs     int64                  `protobuf:"varint,5,opt,name=reset_at_ms,json=resetAtMs,proto3" json:"reset_at_ms,omitempty"`          // unix millis when the client is back to its full allowance
+	Algorithm     string                 `protobuf:"bytes,6,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	MatchedRuleId string                 `protobuf:"bytes,7,opt,name=matched_rule_id,json=matchedRuleId,proto3" json:"matched_rule_id,omitempty"` // empty when the rule's descriptors didn't match, nothing is limited
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLimitStatusResponse) Reset() {
+	*x = GetLimitStatusResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLimitStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLimitStatusResponse) ProtoMessage() {}
+
+func (x *GetLimitStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLimitStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetLimitStatusResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetLimitStatusResponse) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetLimitStatusResponse) GetUsed() int32 {
+	if x != nil {
+		return x.Used
+	}
+	return 0
+}
+
+func (x *GetLimitStatusResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *GetLimitStatusResponse) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *GetLimitStatusResponse) GetResetAtMs() int64 {
+	if x != nil {
+		return x.ResetAtMs
+	}
+	return 0
+}
+
+func (x *GetLimitStatusResponse) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *GetLimitStatusResponse) GetMatchedRuleId() string {
+	if x != nil {
+		return x.MatchedRuleId
+	}
+	return ""
+}
+
+type ResetLimitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RuleId        string                 `protobuf:"bytes,1,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	ClientId      string                 `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"` // empty with no descriptors resets every client
+	Descriptors   []*Descriptor          `protobuf:"bytes,3,rep,name=descriptors,proto3" json:"descriptors,omitempty"`           // picks the counter for rules keyed on descriptors
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetLimitRequest) Reset() {
+	*x = ResetLimitRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetLimitRequest) ProtoMessage() {}
+
+func (x *ResetLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetLimitRequest.ProtoReflect.Descriptor instead.
+func (*ResetLimitRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ResetLimitRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *ResetLimitRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *ResetLimitRequest) GetDescriptors() []*Descriptor {
+	if x != nil {
+		return x.Descriptors
+	}
+	return nil
+}
+
+type ResetLimitResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MatchedRuleId string                 `protobuf:"bytes,1,opt,name=matched_rule_id,json=matchedRuleId,proto3" json:"matched_rule_id,omitempty"`
+	KeysCleared   int32                  `protobuf:"varint,2,opt,name=keys_cleared,json=keysCleared,proto3" json:"keys_cleared,omitempty"` // only counted when resetting every client
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetLimitResponse) Reset() {
+	*x = ResetLimitResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetLimitResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetLimitResponse) ProtoMessage() {}
+
+func (x *ResetLimitResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetLimitResponse.ProtoReflect.Descriptor instead.
+func (*ResetLimitResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ResetLimitResponse) GetMatchedRuleId() string {
+	if x != nil {
+		return x.MatchedRuleId
+	}
+	return ""
+}
+
+func (x *ResetLimitResponse) GetKeysCleared() int32 {
+	if x != nil {
+		return x.KeysCleared
+	}
+	return 0
+}
+
+type RefundRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Amount        int32                  `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`                                   // units to give back, defaults to 1
+	Descriptors   []*Descriptor          `protobuf:"bytes,4,rep,name=descriptors,proto3" json:"descriptors,omitempty"`                          // same as the CheckLimit being refunded
+	ConsumedAtMs  int64                  `protobuf:"varint,5,opt,name=consumed_at_ms,json=consumedAtMs,proto3" json:"consumed_at_ms,omitempty"` // unix millis of that CheckLimit, defaults to now; windowed algorithms refund into its window
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundRequest) Reset() {
+	*x = RefundRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundRequest) ProtoMessage() {}
+
+func (x *RefundRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundRequest.ProtoReflect.Descriptor instead.
+func (*RefundRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *RefundRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *RefundRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *RefundRequest) GetAmount() int32 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RefundRequest) GetDescriptors() []*Descriptor {
+	if x != nil {
+		return x.Descriptors
+	}
+	return nil
+}
+
+func (x *RefundRequest) GetConsumedAtMs() int64 {
+	if x != nil {
+		return x.ConsumedAtMs
+	}
+	return 0
+}
+
+type RefundResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Refunded      int32                  `protobuf:"varint,1,opt,name=refunded,proto3" json:"refunded,omitempty"` // units actually given back, fewer than amount when fewer were in use
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefundResponse) Reset() {
+	*x = RefundResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefundResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefundResponse) ProtoMessage() {}
+
+func (x *RefundResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefundResponse.ProtoReflect.Descriptor instead.
+func (*RefundResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RefundResponse) GetRefunded() int32 {
+	if x != nil {
+		return x.Refunded
+	}
+	return 0
+}
+
+type AcquireConcurrencyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`          // who is starting work
+	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`                // an "in_flight" rule (e.g. "reports")
+	LeaseTtlMs    int64                  `protobuf:"varint,3,opt,name=lease_ttl_ms,json=leaseTtlMs,proto3" json:"lease_ttl_ms,omitempty"` // optional, defaults to the rule's window; slot is freed after this even without a release
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcquireConcurrencyRequest) Reset() {
+	*x = AcquireConcurrencyRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcquireConcurrencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcquireConcurrencyRequest) ProtoMessage() {}
+
+func (x *AcquireConcurrencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcquireConcurrencyRequest.ProtoReflect.Descriptor instead.
+func (*AcquireConcurrencyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AcquireConcurrencyRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *AcquireConcurrencyRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *AcquireConcurrencyRequest) GetLeaseTtlMs() int64 {
+	if x != nil {
+		return x.LeaseTtlMs
+	}
+	return 0
+}
+
+type AcquireConcurrencyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Acquired      bool                   `protobuf:"varint,1,opt,name=acquired,proto3" json:"acquired,omitempty"`                               // did the caller get a slot?
+	LeaseId       string                 `protobuf:"bytes,2,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`                   // pass to ReleaseConcurrency when the work is done
+	Remaining     int32                  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`                             // free slots left after this acquire
+	RetryAfterMs  int64                  `protobuf:"varint,4,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"` // if not acquired, when the oldest lease expires at the latest
+	ExpiresAtMs   int64                  `protobuf:"varint,5,opt,name=expires_at_ms,json=expiresAtMs,proto3" json:"expires_at_ms,omitempty"`    // unix millis when this lease lapses on its own
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AcquireConcurrencyResponse) Reset() {
+	*x = AcquireConcurrencyResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AcquireConcurrencyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcquireConcurrencyResponse) ProtoMessage() {}
+
+func (x *AcquireConcurrencyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcquireConcurrencyResponse.ProtoReflect.Descriptor instead.
+func (*AcquireConcurrencyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AcquireConcurrencyResponse) GetAcquired() bool {
+	if x != nil {
+		return x.Acquired
+	}
+	return false
+}
+
+func (x *AcquireConcurrencyResponse) GetLeaseId() string {
+	if x != nil {
+		return x.LeaseId
+	}
+	return ""
+}
+
+func (x *AcquireConcurrencyResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *AcquireConcurrencyResponse) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *AcquireConcurrencyResponse) GetExpiresAtMs() int64 {
+	if x != nil {
+		return x.ExpiresAtMs
+	}
+	return 0
+}
+
+type ReleaseConcurrencyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	LeaseId       string                 `protobuf:"bytes,3,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"` // from AcquireConcurrencyResponse
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseConcurrencyRequest) Reset() {
+	*x = ReleaseConcurrencyRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseConcurrencyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseConcurrencyRequest) ProtoMessage() {}
+
+func (x *ReleaseConcurrencyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseConcurrencyRequest.ProtoReflect.Descriptor instead.
+func (*ReleaseConcurrencyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReleaseConcurrencyRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *ReleaseConcurrencyRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *ReleaseConcurrencyRequest) GetLeaseId() string {
+	if x != nil {
+		return x.LeaseId
+	}
+	return ""
+}
+
+type ReleaseConcurrencyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Released      bool                   `protobuf:"varint,1,opt,name=released,proto3" json:"released,omitempty"` // false if the lease had already expired or been released
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReleaseConcurrencyResponse) Reset() {
+	*x = ReleaseConcurrencyResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReleaseConcurrencyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReleaseConcurrencyResponse) ProtoMessage() {}
+
+func (x *ReleaseConcurrencyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReleaseConcurrencyResponse.ProtoReflect.Descriptor instead.
+func (*ReleaseConcurrencyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ReleaseConcurrencyResponse) GetReleased() bool {
+	if x != nil {
+		return x.Released
+	}
+	return false
+}
+
+type LeaseQuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	Permits       int32                  `protobuf:"varint,3,opt,name=permits,proto3" json:"permits,omitempty"`          // how many to lease, fewer are granted if fewer are left
+	TtlMs         int64                  `protobuf:"varint,4,opt,name=ttl_ms,json=ttlMs,proto3" json:"ttl_ms,omitempty"` // optional, defaults to 1s; unspent permits are void after this
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaseQuotaRequest) Reset() {
+	*x = LeaseQuotaRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseQuotaRequest) ProtoMessage() {}
+
+func (x *LeaseQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseQuotaRequest.ProtoReflect.Descriptor instead.
+func (*LeaseQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *LeaseQuotaRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *LeaseQuotaRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *LeaseQuotaRequest) GetPermits() int32 {
+	if x != nil {
+		return x.Permits
+	}
+	return 0
+}
+
+func (x *LeaseQuotaRequest) GetTtlMs() int64 {
+	if x != nil {
+		return x.TtlMs
+	}
+	return 0
+}
+
+type LeaseQuotaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Granted       int32                  `protobuf:"varint,1,opt,name=granted,proto3" json:"granted,omitempty"`                                 // permits leased, 0 if the rule has none left
+	LeaseId       string                 `protobuf:"bytes,2,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"`                   // pass to ReturnQuota with the unused count
+	ExpiresAtMs   int64                  `protobuf:"varint,3,opt,name=expires_at_ms,json=expiresAtMs,proto3" json:"expires_at_ms,omitempty"`    // unix millis after which the permits must not be spent
+	Remaining     int32                  `protobuf:"varint,4,opt,name=remaining,proto3" json:"remaining,omitempty"`                             // left on the rule after this lease
+	RetryAfterMs  int64                  `protobuf:"varint,5,opt,name=retry_after_ms,json=retryAfterMs,proto3" json:"retry_after_ms,omitempty"` // if nothing was granted, wait this long before leasing again
+	Algorithm     string                 `protobuf:"bytes,6,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LeaseQuotaResponse) Reset() {
+	*x = LeaseQuotaResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LeaseQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LeaseQuotaResponse) ProtoMessage() {}
+
+func (x *LeaseQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LeaseQuotaResponse.ProtoReflect.Descriptor instead.
+func (*LeaseQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *LeaseQuotaResponse) GetGranted() int32 {
+	if x != nil {
+		return x.Granted
+	}
+	return 0
+}
+
+func (x *LeaseQuotaResponse) GetLeaseId() string {
+	if x != nil {
+		return x.LeaseId
+	}
+	return ""
+}
+
+func (x *LeaseQuotaResponse) GetExpiresAtMs() int64 {
+	if x != nil {
+		return x.ExpiresAtMs
+	}
+	return 0
+}
+
+func (x *LeaseQuotaResponse) GetRemaining() int32 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *LeaseQuotaResponse) GetRetryAfterMs() int64 {
+	if x != nil {
+		return x.RetryAfterMs
+	}
+	return 0
+}
+
+func (x *LeaseQuotaResponse) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+type ReturnQuotaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ClientId      string                 `protobuf:"bytes,1,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"`
+	LeaseId       string                 `protobuf:"bytes,3,opt,name=lease_id,json=leaseId,proto3" json:"lease_id,omitempty"` // from LeaseQuotaResponse
+	Unused        int32                  `protobuf:"varint,4,opt,name=unused,proto3" json:"unused,omitempty"`                 // permits never spent
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnQuotaRequest) Reset() {
+	*x = ReturnQuotaRequest{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnQuotaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnQuotaRequest) ProtoMessage() {}
+
+func (x *ReturnQuotaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnQuotaRequest.ProtoReflect.Descriptor instead.
+func (*ReturnQuotaRequest) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *ReturnQuotaRequest) GetClientId() string {
+	if x != nil {
+		return x.ClientId
+	}
+	return ""
+}
+
+func (x *ReturnQuotaRequest) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *ReturnQuotaRequest) GetLeaseId() string {
+	if x != nil {
+		return x.LeaseId
+	}
+	return ""
+}
+
+func (x *ReturnQuotaRequest) GetUnused() int32 {
+	if x != nil {
+		return x.Unused
+	}
+	return 0
+}
+
+type ReturnQuotaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Returned      int32                  `protobuf:"varint,1,opt,name=returned,proto3" json:"returned,omitempty"` // permits given back to the rule, capped at what the lease granted
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReturnQuotaResponse) Reset() {
+	*x = ReturnQuotaResponse{}
+	mi := &file_proto_ratelimiter_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReturnQuotaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReturnQuotaResponse) ProtoMessage() {}
+
+func (x *ReturnQuotaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_ratelimiter_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReturnQuotaResponse.ProtoReflect.Descriptor instead.
+func (*ReturnQuotaResponse) Descriptor() ([]byte, []int) {
+	return file_proto_ratelimiter_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ReturnQuotaResponse) GetReturned() int32 {
+	if x != nil {
+		return x.Returned
+	}
+	return 0
+}
+
+var File_proto_ratelimiter_proto protoreflect.FileDescriptor
+
+const file_proto_ratelimiter_proto_rawDesc = "" +
+	"\n" +
+	"\x17proto/ratelimiter.proto\x12\vratelimiter\"\x98\x01\n" +
+	"\x11CheckLimitRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x12\n" +
+	"\x04cost\x18\x03 \x01(\x05R\x04cost\x129\n" +
+	"\vdescriptors\x18\x04 \x03(\v2\x17.ratelimiter.DescriptorR\vdescriptors\"4\n" +
+	"\n" +
+	"Descriptor\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value\"\xf4\x01\n" +
+	"\x12CheckLimitResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x1c\n" +
+	"\tremaining\x18\x02 \x01(\x05R\tremaining\x12$\n" +
+	"\x0eretry_after_ms\x18\x03 \x01(\x03R\fretryAfterMs\x12\x1c\n" +
+	"\talgorithm\x18\x04 \x01(\tR\talgorithm\x12\x1e\n" +
+	"\vreset_at_ms\x18\x05 \x01(\x03R\tresetAtMs\x12\x1a\n" +
+	"\bdegraded\x18\x06 \x01(\bR\bdegraded\x12&\n" +
+	"\x0fmatched_rule_id\x18\a \x01(\tR\rmatchedRuleId\"`\n" +
+	"\x12CheckLimitsRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x19\n" +
+	"\brule_ids\x18\x02 \x03(\tR\aruleIds\x12\x12\n" +
+	"\x04cost\x18\x03 \x01(\x05R\x04cost\"\xdd\x01\n" +
+	"\n" +
+	"RuleResult\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x18\n" +
+	"\aallowed\x18\x02 \x01(\bR\aallowed\x12\x1c\n" +
+	"\tremaining\x18\x03 \x01(\x05R\tremaining\x12$\n" +
+	"\x0eretry_after_ms\x18\x04 \x01(\x03R\fretryAfterMs\x12\x1c\n" +
+	"\talgorithm\x18\x05 \x01(\tR\talgorithm\x12\x1e\n" +
+	"\vreset_at_ms\x18\x06 \x01(\x03R\tresetAtMs\x12\x1a\n" +
+	"\bdegraded\x18\a \x01(\bR\bdegraded\"\xa4\x01\n" +
+	"\x13CheckLimitsResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x12$\n" +
+	"\x0eretry_after_ms\x18\x02 \x01(\x03R\fretryAfterMs\x121\n" +
+	"\aresults\x18\x03 \x03(\v2\x17.ratelimiter.RuleResultR\aresults\x12\x1a\n" +
+	"\bdegraded\x18\x04 \x01(\bR\bdegraded\"N\n" +
+	"\x16BatchCheckLimitRequest\x124\n" +
+	"\x05items\x18\x01 \x03(\v2\x1e.ratelimiter.CheckLimitRequestR\x05items\"T\n" +
+	"\x17BatchCheckLimitResponse\x129\n" +
+	"\aresults\x18\x01 \x03(\v2\x1f.ratelimiter.CheckLimitResponseR\aresults\"v\n" +
+	"\x17StreamCheckLimitRequest\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x124\n" +
+	"\x05check\x18\x02 \x01(\v2\x1e.ratelimiter.CheckLimitRequestR\x05check\"\xaf\x01\n" +
+	"\x18StreamCheckLimitResponse\x12%\n" +
+	"\x0ecorrelation_id\x18\x01 \x01(\tR\rcorrelationId\x127\n" +
+	"\x06result\x18\x02 \x01(\v2\x1f.ratelimiter.CheckLimitResponseR\x06result\x12\x1d\n" +
+	"\n" +
+	"error_code\x18\x03 \x01(\x05R\terrorCode\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\"\x88\x01\n" +
+	"\x15GetLimitStatusRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x129\n" +
+	"\vdescriptors\x18\x03 \x03(\v2\x17.ratelimiter.DescriptorR\vdescriptors\"\xec\x01\n" +
+	"\x16GetLimitStatusResponse\x12\x14\n" +
+	"\x05limit\x18\x01 \x01(\x05R\x05limit\x12\x12\n" +
+	"\x04used\x18\x02 \x01(\x05R\x04used\x12\x1c\n" +
+	"\tremaining\x18\x03 \x01(\x05R\tremaining\x12$\n" +
+	"\x0eretry_after_ms\x18\x04 \x01(\x03R\fretryAfterMs\x12\x1e\n" +
+	"\vreset_at_ms\x18\x05 \x01(\x03R\tresetAtMs\x12\x1c\n" +
+	"\talgorithm\x18\x06 \x01(\tR\talgorithm\x12&\n" +
+	"\x0fmatched_rule_id\x18\a \x01(\tR\rmatchedRuleId\"\x84\x01\n" +
+	"\x11ResetLimitRequest\x12\x17\n" +
+	"\arule_id\x18\x01 \x01(\tR\x06ruleId\x12\x1b\n" +
+	"\tclient_id\x18\x02 \x01(\tR\bclientId\x129\n" +
+	"\vdescriptors\x18\x03 \x03(\v2\x17.ratelimiter.DescriptorR\vdescriptors\"_\n" +
+	"\x12ResetLimitResponse\x12&\n" +
+	"\x0fmatched_rule_id\x18\x01 \x01(\tR\rmatchedRuleId\x12!\n" +
+	"\fkeys_cleared\x18\x02 \x01(\x05R\vkeysCleared\"\xbe\x01\n" +
+	"\rRefundRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x16\n" +
+	"\x06amount\x18\x03 \x01(\x05R\x06amount\x129\n" +
+	"\vdescriptors\x18\x04 \x03(\v2\x17.ratelimiter.DescriptorR\vdescriptors\x12$\n" +
+	"\x0econsumed_at_ms\x18\x05 \x01(\x03R\fconsumedAtMs\",\n" +
+	"\x0eRefundResponse\x12\x1a\n" +
+	"\brefunded\x18\x01 \x01(\x05R\brefunded\"s\n" +
+	"\x19AcquireConcurrencyRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12 \n" +
+	"\flease_ttl_ms\x18\x03 \x01(\x03R\n" +
+	"leaseTtlMs\"\xbb\x01\n" +
+	"\x1aAcquireConcurrencyResponse\x12\x1a\n" +
+	"\bacquired\x18\x01 \x01(\bR\bacquired\x12\x19\n" +
+	"\blease_id\x18\x02 \x01(\tR\aleaseId\x12\x1c\n" +
+	"\tremaining\x18\x03 \x01(\x05R\tremaining\x12$\n" +
+	"\x0eretry_after_ms\x18\x04 \x01(\x03R\fretryAfterMs\x12\"\n" +
+	"\rexpires_at_ms\x18\x05 \x01(\x03R\vexpiresAtMs\"l\n" +
+	"\x19ReleaseConcurrencyRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x19\n" +
+	"\blease_id\x18\x03 \x01(\tR\aleaseId\"8\n" +
+	"\x1aReleaseConcurrencyResponse\x12\x1a\n" +
+	"\breleased\x18\x01 \x01(\bR\breleased\"z\n" +
+	"\x11LeaseQuotaRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x18\n" +
+	"\apermits\x18\x03 \x01(\x05R\apermits\x12\x15\n" +
+	"\x06ttl_ms\x18\x04 \x01(\x03R\x05ttlMs\"\xcf\x01\n" +
+	"\x12LeaseQuotaResponse\x12\x18\n" +
+	"\agranted\x18\x01 \x01(\x05R\agranted\x12\x19\n" +
+	"\blease_id\x18\x02 \x01(\tR\aleaseId\x12\"\n" +
+	"\rexpires_at_ms\x18\x03 \x01(\x03R\vexpiresAtMs\x12\x1c\n" +
+	"\tremaining\x18\x04 \x01(\x05R\tremaining\x12$\n" +
+	"\x0eretry_after_ms\x18\x05 \x01(\x03R\fretryAfterMs\x12\x1c\n" +
+	"\talgorithm\x18\x06 \x01(\tR\talgorithm\"}\n" +
+	"\x12ReturnQuotaRequest\x12\x1b\n" +
+	"\tclient_id\x18\x01 \x01(\tR\bclientId\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x19\n" +
+	"\blease_id\x18\x03 \x01(\tR\aleaseId\x12\x16\n" +
+	"\x06unused\x18\x04 \x01(\x05R\x06unused\"1\n" +
+	"\x13ReturnQuotaResponse\x12\x1a\n" +
+	"\breturned\x18\x01 \x01(\x05R\breturned2\xcd\a\n" +
+	"\vRateLimiter\x12M\n" +
+	"\n" +
+	"CheckLimit\x12\x1e.ratelimiter.CheckLimitRequest\x1a\x1f.ratelimiter.CheckLimitResponse\x12P\n" +
+	"\vCheckLimits\x12\x1f.ratelimiter.CheckLimitsRequest\x1a .ratelimiter.CheckLimitsResponse\x12\\\n" +
+	"\x0fBatchCheckLimit\x12#.ratelimiter.BatchCheckLimitRequest\x1a$.ratelimiter.BatchCheckLimitResponse\x12c\n" +
+	"\x10StreamCheckLimit\x12$.ratelimiter.StreamCheckLimitRequest\x1a%.ratelimiter.StreamCheckLimitResponse(\x010\x01\x12Y\n" +
+	"\x0eGetLimitStatus\x12\".ratelimiter.GetLimitStatusRequest\x1a#.ratelimiter.GetLimitStatusResponse\x12M\n" +
+	"\n" +
+	"ResetLimit\x12\x1e.ratelimiter.ResetLimitRequest\x1a\x1f.ratelimiter.ResetLimitResponse\x12A\n" +
+	"\x06Refund\x12\x1a.ratelimiter.RefundRequest\x1a\x1b.ratelimiter.RefundResponse\x12e\n" +
+	"\x12AcquireConcurrency\x12&.ratelimiter.AcquireConcurrencyRequest\x1a'.ratelimiter.AcquireConcurrencyResponse\x12e\n" +
+	"\x12ReleaseConcurrency\x12&.ratelimiter.ReleaseConcurrencyRequest\x1a'.ratelimiter.ReleaseConcurrencyResponse\x12M\n" +
 	"\n" +
-	"CheckLimit\x12\x1e.ratelimiter.CheckLimitRequest\x1a\x1f.ratelimiter.CheckLimitResponseB\x1fZ\x1dgithub.com/cynkin/rlaas/protob\x06proto3"
+	"LeaseQuota\x12\x1e.ratelimiter.LeaseQuotaRequest\x1a\x1f.ratelimiter.LeaseQuotaResponse\x12P\n" +
+	"\vReturnQuota\x12\x1f.ratelimiter.ReturnQuotaRequest\x1a .ratelimiter.ReturnQuotaResponseB\x1fZ\x1dgithub.com/cynkin/rlaas/protob\x06proto3"
 
 var (
 	file_proto_ratelimiter_proto_rawDescOnce sync.Once
@@ -170,19 +1695,70 @@ func file_proto_ratelimiter_proto_rawDescGZIP() []byte {
 	return file_proto_ratelimiter_proto_rawDescData
 }
 
-var file_proto_ratelimiter_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_proto_ratelimiter_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_proto_ratelimiter_proto_goTypes = []any{
-	(*CheckLimitRequest)(nil),  // 0: ratelimiter.CheckLimitRequest
-	(*CheckLimitResponse)(nil), // 1: ratelimiter.CheckLimitResponse
+	(*CheckLimitRequest)(nil),          // 0: ratelimiter.CheckLimitRequest
+	(*Descriptor)(nil),                 // 1: ratelimiter.Descriptor
+	(*CheckLimitResponse)(nil),         // 2: ratelimiter.CheckLimitResponse
+	(*CheckLimitsRequest)(nil),         // 3: ratelimiter.CheckLimitsRequest
+	(*RuleResult)(nil),                 // 4: ratelimiter.RuleResult
+	(*CheckLimitsResponse)(nil),        // 5: ratelimiter.CheckLimitsResponse
+	(*BatchCheckLimitRequest)(nil),     // 6: ratelimiter.BatchCheckLimitRequest
+	(*BatchCheckLimitResponse)(nil),    // 7: ratelimiter.BatchCheckLimitResponse
+	(*StreamCheckLimitRequest)(nil),    // 8: ratelimiter.StreamCheckLimitRequest
+	(*StreamCheckLimitResponse)(nil),   // 9: ratelimiter.StreamCheckLimitResponse
+	(*GetLimitStatusRequest)(nil),      // 10: ratelimiter.GetLimitStatusRequest
+	(*GetLimitStatusResponse)(nil),     // 11: ratelimiter.GetLimitStatusResponse
+	(*ResetLimitRequest)(nil),          // 12: ratelimiter.ResetLimitRequest
+	(*ResetLimitResponse)(nil),         // 13: ratelimiter.ResetLimitResponse
+	(*RefundRequest)(nil),              // 14: ratelimiter.RefundRequest
+	(*RefundResponse)(nil),             // 15: ratelimiter.RefundResponse
+	(*AcquireConcurrencyRequest)(nil),  // 16: ratelimiter.AcquireConcurrencyRequest
+	(*AcquireConcurrencyResponse)(nil), // 17: ratelimiter.AcquireConcurrencyResponse
+	(*ReleaseConcurrencyRequest)(nil),  // 18: ratelimiter.ReleaseConcurrencyRequest
+	(*ReleaseConcurrencyResponse)(nil), // 19: ratelimiter.ReleaseConcurrencyResponse
+	(*LeaseQuotaRequest)(nil),          // 20: ratelimiter.LeaseQuotaRequest
+	(*LeaseQuotaResponse)(nil),         // 21: ratelimiter.LeaseQuotaResponse
+	(*ReturnQuotaRequest)(nil),         // 22: ratelimiter.ReturnQuotaRequest
+	(*ReturnQuotaResponse)(nil),        // 23: ratelimiter.ReturnQuotaResponse
 }
 var file_proto_ratelimiter_proto_depIdxs = []int32{
-	0, // 0: ratelimiter.RateLimiter.CheckLimit:input_type -> ratelimiter.CheckLimitRequest
-	1, // 1: ratelimiter.RateLimiter.CheckLimit:output_type -> ratelimiter.CheckLimitResponse
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	1,  // 0: ratelimiter.CheckLimitRequest.descriptors:type_name -> ratelimiter.Descriptor
+	4,  // 1: ratelimiter.CheckLimitsResponse.results:type_name -> ratelimiter.RuleResult
+	0,  // 2: ratelimiter.BatchCheckLimitRequest.items:type_name -> ratelimiter.CheckLimitRequest
+	2,  // 3: ratelimiter.BatchCheckLimitResponse.results:type_name -> ratelimiter.CheckLimitResponse
+	0,  // 4: ratelimiter.StreamCheckLimitRequest.check:type_name -> ratelimiter.CheckLimitRequest
+	2,  // 5: ratelimiter.StreamCheckLimitResponse.result:type_name -> ratelimiter.CheckLimitResponse
+	1,  // 6: ratelimiter.GetLimitStatusRequest.descriptors:type_name -> ratelimiter.Descriptor
+	1,  // 7: ratelimiter.ResetLimitRequest.descriptors:type_name -> ratelimiter.Descriptor
+	1,  // 8: ratelimiter.RefundRequest.descriptors:type_name -> ratelimiter.Descriptor
+	0,  // 9: ratelimiter.RateLimiter.CheckLimit:input_type -> ratelimiter.CheckLimitRequest
+	3,  // 10: ratelimiter.RateLimiter.CheckLimits:input_type -> ratelimiter.CheckLimitsRequest
+	6,  // 11: ratelimiter.RateLimiter.BatchCheckLimit:input_type -> ratelimiter.BatchCheckLimitRequest
+	8,  // 12: ratelimiter.RateLimiter.StreamCheckLimit:input_type -> ratelimiter.StreamCheckLimitRequest
+	10, // 13: ratelimiter.RateLimiter.GetLimitStatus:input_type -> ratelimiter.GetLimitStatusRequest
+	12, // 14: ratelimiter.RateLimiter.ResetLimit:input_type -> ratelimiter.ResetLimitRequest
+	14, // 15: ratelimiter.RateLimiter.Refund:input_type -> ratelimiter.RefundRequest
+	16, // 16: ratelimiter.RateLimiter.AcquireConcurrency:input_type -> ratelimiter.AcquireConcurrencyRequest
+	18, // 17: ratelimiter.RateLimiter.ReleaseConcurrency:input_type -> ratelimiter.ReleaseConcurrencyRequest
+	20, // 18: ratelimiter.RateLimiter.LeaseQuota:input_type -> ratelimiter.LeaseQuotaRequest
+	22, // 19: ratelimiter.RateLimiter.ReturnQuota:input_type -> ratelimiter.ReturnQuotaRequest
+	2,  // 20: ratelimiter.RateLimiter.CheckLimit:output_type -> ratelimiter.CheckLimitResponse
+	5,  // 21: ratelimiter.RateLimiter.CheckLimits:output_type -> ratelimiter.CheckLimitsResponse
+	7,  // 22: ratelimiter.RateLimiter.BatchCheckLimit:output_type -> ratelimiter.BatchCheckLimitResponse
+	9,  // 23: ratelimiter.RateLimiter.StreamCheckLimit:output_type -> ratelimiter.StreamCheckLimitResponse
+	11, // 24: ratelimiter.RateLimiter.GetLimitStatus:output_type -> ratelimiter.GetLimitStatusResponse
+	13, // 25: ratelimiter.RateLimiter.ResetLimit:output_type -> ratelimiter.ResetLimitResponse
+	15, // 26: ratelimiter.RateLimiter.Refund:output_type -> ratelimiter.RefundResponse
+	17, // 27: ratelimiter.RateLimiter.AcquireConcurrency:output_type -> ratelimiter.AcquireConcurrencyResponse
+	19, // 28: ratelimiter.RateLimiter.ReleaseConcurrency:output_type -> ratelimiter.ReleaseConcurrencyResponse
+	21, // 29: ratelimiter.RateLimiter.LeaseQuota:output_type -> ratelimiter.LeaseQuotaResponse
+	23, // 30: ratelimiter.RateLimiter.ReturnQuota:output_type -> ratelimiter.ReturnQuotaResponse
+	20, // [20:31] is the sub-list for method output_type
+	9,  // [9:20] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
 }
 
 func init() { file_proto_ratelimiter_proto_init() }
@@ -196,7 +1772,7 @@ func file_proto_ratelimiter_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_ratelimiter_proto_rawDesc), len(file_proto_ratelimiter_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   2,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
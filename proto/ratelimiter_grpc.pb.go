@@ -19,7 +19,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RateLimiter_CheckLimit_FullMethodName = "/ratelimiter.RateLimiter/CheckLimit"
+	RateLimiter_CheckLimit_FullMethodName         = "/ratelimiter.RateLimiter/CheckLimit"
+	RateLimiter_CheckLimits_FullMethodName        = "/ratelimiter.RateLimiter/CheckLimits"
+	RateLimiter_BatchCheckLimit_FullMethodName    = "/ratelimiter.RateLimiter/BatchCheckLimit"
+	RateLimiter_StreamCheckLimit_FullMethodName   = "/ratelimiter.RateLimiter/StreamCheckLimit"
+	RateLimiter_GetLimitStatus_FullMethodName     = "/ratelimiter.RateLimiter/GetLimitStatus"
+	RateLimiter_ResetLimit_FullMethodName         = "/ratelimiter.RateLimiter/ResetLimit"
+	RateLimiter_Refund_FullMethodName             = "/ratelimiter.RateLimiter/Refund"
+	RateLimiter_AcquireConcurrency_FullMethodName = "/ratelimiter.RateLimiter/AcquireConcurrency"
+	RateLimiter_ReleaseConcurrency_FullMethodName = "/ratelimiter.RateLimiter/ReleaseConcurrency"
+	RateLimiter_LeaseQuota_FullMethodName         = "/ratelimiter.RateLimiter/LeaseQuota"
+	RateLimiter_ReturnQuota_FullMethodName        = "/ratelimiter.RateLimiter/ReturnQuota"
 )
 
 // RateLimiterClient is the client API for RateLimiter service.
@@ -29,6 +39,36 @@ const (
 // The one RPC that every caller uses
 type RateLimiterClient interface {
 	CheckLimit(ctx context.Context, in *CheckLimitRequest, opts ...grpc.CallOption) (*CheckLimitResponse, error)
+	// Check several rules at once (e.g. per-second, per-hour, per-day). Either all
+	// of them consume or none does
+	CheckLimits(ctx context.Context, in *CheckLimitsRequest, opts ...grpc.CallOption) (*CheckLimitsResponse, error)
+	// Many independent checks in one call and one Redis round trip, e.g. a gateway
+	// fanning out to dozens of tenants. Unlike CheckLimits each item consumes on its own
+	BatchCheckLimit(ctx context.Context, in *BatchCheckLimitRequest, opts ...grpc.CallOption) (*BatchCheckLimitResponse, error)
+	// One long-lived stream for chatty callers like sidecars. Decisions come back
+	// as soon as they are made, tagged with the request's correlation ID, so not
+	// necessarily in request order
+	StreamCheckLimit(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamCheckLimitRequest, StreamCheckLimitResponse], error)
+	// Where a client stands under a rule, without consuming anything (e.g. "3
+	// exports left this hour")
+	GetLimitStatus(ctx context.Context, in *GetLimitStatusRequest, opts ...grpc.CallOption) (*GetLimitStatusResponse, error)
+	// Give a client, or every client, its full allowance back under a rule.
+	// Unknown rule IDs are NotFound, they never fall back to "default"
+	ResetLimit(ctx context.Context, in *ResetLimitRequest, opts ...grpc.CallOption) (*ResetLimitResponse, error)
+	// Give back units a check consumed when the work it guarded never happened.
+	// Capped so usage never goes below zero
+	Refund(ctx context.Context, in *RefundRequest, opts ...grpc.CallOption) (*RefundResponse, error)
+	// Concurrency limiting for "in_flight" rules: take a slot before the work
+	// starts and give it back when it ends. Slots live in Redis, so while it is
+	// unavailable these fail with Unavailable whatever the rule's failure mode
+	AcquireConcurrency(ctx context.Context, in *AcquireConcurrencyRequest, opts ...grpc.CallOption) (*AcquireConcurrencyResponse, error)
+	ReleaseConcurrency(ctx context.Context, in *ReleaseConcurrencyRequest, opts ...grpc.CallOption) (*ReleaseConcurrencyResponse, error)
+	// Quota leasing for high-volume callers: take a batch of permits in one call,
+	// spend them locally and hand back whatever is left. Leases live in Redis, so
+	// while it is unavailable these fail with Unavailable whatever the rule's
+	// failure mode; callers can fall back to CheckLimit, which honors it
+	LeaseQuota(ctx context.Context, in *LeaseQuotaRequest, opts ...grpc.CallOption) (*LeaseQuotaResponse, error)
+	ReturnQuota(ctx context.Context, in *ReturnQuotaRequest, opts ...grpc.CallOption) (*ReturnQuotaResponse, error)
 }
 
 type rateLimiterClient struct {
@@ -49,6 +89,109 @@ func (c *rateLimiterClient) CheckLimit(ctx context.Context, in *CheckLimitReques
 	return out, nil
 }
 
+func (c *rateLimiterClient) CheckLimits(ctx context.Context, in *CheckLimitsRequest, opts ...grpc.CallOption) (*CheckLimitsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckLimitsResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_CheckLimits_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) BatchCheckLimit(ctx context.Context, in *BatchCheckLimitRequest, opts ...grpc.CallOption) (*BatchCheckLimitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(BatchCheckLimitResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_BatchCheckLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) StreamCheckLimit(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[StreamCheckLimitRequest, StreamCheckLimitResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RateLimiter_ServiceDesc.Streams[0], RateLimiter_StreamCheckLimit_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamCheckLimitRequest, StreamCheckLimitResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateLimiter_StreamCheckLimitClient = grpc.BidiStreamingClient[StreamCheckLimitRequest, StreamCheckLimitResponse]
+
+func (c *rateLimiterClient) GetLimitStatus(ctx context.Context, in *GetLimitStatusRequest, opts ...grpc.CallOption) (*GetLimitStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetLimitStatusResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_GetLimitStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) ResetLimit(ctx context.Context, in *ResetLimitRequest, opts ...grpc.CallOption) (*ResetLimitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResetLimitResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_ResetLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) Refund(ctx context.Context, in *RefundRequest, opts ...grpc.CallOption) (*RefundResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefundResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_Refund_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) AcquireConcurrency(ctx context.Context, in *AcquireConcurrencyRequest, opts ...grpc.CallOption) (*AcquireConcurrencyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AcquireConcurrencyResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_AcquireConcurrency_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) ReleaseConcurrency(ctx context.Context, in *ReleaseConcurrencyRequest, opts ...grpc.CallOption) (*ReleaseConcurrencyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReleaseConcurrencyResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_ReleaseConcurrency_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) LeaseQuota(ctx context.Context, in *LeaseQuotaRequest, opts ...grpc.CallOption) (*LeaseQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LeaseQuotaResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_LeaseQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rateLimiterClient) ReturnQuota(ctx context.Context, in *ReturnQuotaRequest, opts ...grpc.CallOption) (*ReturnQuotaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReturnQuotaResponse)
+	err := c.cc.Invoke(ctx, RateLimiter_ReturnQuota_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RateLimiterServer is the server API for RateLimiter service.
 // All implementations must embed UnimplementedRateLimiterServer
 // for forward compatibility.
@@ -56,6 +199,36 @@ func (c *rateLimiterClient) CheckLimit(ctx context.Context, in *CheckLimitReques
 // The one RPC that every caller uses
 type RateLimiterServer interface {
 	CheckLimit(context.Context, *CheckLimitRequest) (*CheckLimitResponse, error)
+	// Check several rules at once (e.g. per-second, per-hour, per-day). Either all
+	// of them consume or none does
+	CheckLimits(context.Context, *CheckLimitsRequest) (*CheckLimitsResponse, error)
+	// Many independent checks in one call and one Redis round trip, e.g. a gateway
+	// fanning out to dozens of tenants. Unlike CheckLimits each item consumes on its own
+	BatchCheckLimit(context.Context, *BatchCheckLimitRequest) (*BatchCheckLimitResponse, error)
+	// One long-lived stream for chatty callers like sidecars. Decisions come back
+	// as soon as they are made, tagged with the request's correlation ID, so not
+	// necessarily in request order
+	StreamCheckLimit(grpc.BidiStreamingServer[StreamCheckLimitRequest, StreamCheckLimitResponse]) error
+	// Where a client stands under a rule, without consuming anything (e.g. "3
+	// exports left this hour")
+	GetLimitStatus(context.Context, *GetLimitStatusRequest) (*GetLimitStatusResponse, error)
+	// Give a client, or every client, its full allowance back under a rule.
+	// Unknown rule IDs are NotFound, they never fall back to "default"
+	ResetLimit(context.Context, *ResetLimitRequest) (*ResetLimitResponse, error)
+	// Give back units a check consumed when the work it guarded never happened.
+	// Capped so usage never goes below zero
+	Refund(context.Context, *RefundRequest) (*RefundResponse, error)
+	// Concurrency limiting for "in_flight" rules: take a slot before the work
+	// starts and give it back when it ends. Slots live in Redis, so while it is
+	// unavailable these fail with Unavailable whatever the rule's failure mode
+	AcquireConcurrency(context.Context, *AcquireConcurrencyRequest) (*AcquireConcurrencyResponse, error)
+	ReleaseConcurrency(context.Context, *ReleaseConcurrencyRequest) (*ReleaseConcurrencyResponse, error)
+	// Quota leasing for high-volume callers: take a batch of permits in one call,
+	// spend them locally and hand back whatever is left. Leases live in Redis, so
+	// while it is unavailable these fail with Unavailable whatever the rule's
+	// failure mode; callers can fall back to CheckLimit, which honors it
+	LeaseQuota(context.Context, *LeaseQuotaRequest) (*LeaseQuotaResponse, error)
+	ReturnQuota(context.Context, *ReturnQuotaRequest) (*ReturnQuotaResponse, error)
 	mustEmbedUnimplementedRateLimiterServer()
 }
 
@@ -69,6 +242,36 @@ type UnimplementedRateLimiterServer struct{}
 func (UnimplementedRateLimiterServer) CheckLimit(context.Context, *CheckLimitRequest) (*CheckLimitResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method CheckLimit not implemented")
 }
+func (UnimplementedRateLimiterServer) CheckLimits(context.Context, *CheckLimitsRequest) (*CheckLimitsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckLimits not implemented")
+}
+func (UnimplementedRateLimiterServer) BatchCheckLimit(context.Context, *BatchCheckLimitRequest) (*BatchCheckLimitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method BatchCheckLimit not implemented")
+}
+func (UnimplementedRateLimiterServer) StreamCheckLimit(grpc.BidiStreamingServer[StreamCheckLimitRequest, StreamCheckLimitResponse]) error {
+	return status.Error(codes.Unimplemented, "method StreamCheckLimit not implemented")
+}
+func (UnimplementedRateLimiterServer) GetLimitStatus(context.Context, *GetLimitStatusRequest) (*GetLimitStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetLimitStatus not implemented")
+}
+func (UnimplementedRateLimiterServer) ResetLimit(context.Context, *ResetLimitRequest) (*ResetLimitResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResetLimit not implemented")
+}
+func (UnimplementedRateLimiterServer) Refund(context.Context, *RefundRequest) (*RefundResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Refund not implemented")
+}
+func (UnimplementedRateLimiterServer) AcquireConcurrency(context.Context, *AcquireConcurrencyRequest) (*AcquireConcurrencyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AcquireConcurrency not implemented")
+}
+func (UnimplementedRateLimiterServer) ReleaseConcurrency(context.Context, *ReleaseConcurrencyRequest) (*ReleaseConcurrencyResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReleaseConcurrency not implemented")
+}
+func (UnimplementedRateLimiterServer) LeaseQuota(context.Context, *LeaseQuotaRequest) (*LeaseQuotaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method LeaseQuota not implemented")
+}
+func (UnimplementedRateLimiterServer) ReturnQuota(context.Context, *ReturnQuotaRequest) (*ReturnQuotaResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ReturnQuota not implemented")
+}
 func (UnimplementedRateLimiterServer) mustEmbedUnimplementedRateLimiterServer() {}
 func (UnimplementedRateLimiterServer) testEmbeddedByValue()                     {}
 
@@ -108,6 +311,175 @@ func _RateLimiter_CheckLimit_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RateLimiter_CheckLimits_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckLimitsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).CheckLimits(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_CheckLimits_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).CheckLimits(ctx, req.(*CheckLimitsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_BatchCheckLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(BatchCheckLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).BatchCheckLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_BatchCheckLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).BatchCheckLimit(ctx, req.(*BatchCheckLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_StreamCheckLimit_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RateLimiterServer).StreamCheckLimit(&grpc.GenericServerStream[StreamCheckLimitRequest, StreamCheckLimitResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RateLimiter_StreamCheckLimitServer = grpc.BidiStreamingServer[StreamCheckLimitRequest, StreamCheckLimitResponse]
+
+func _RateLimiter_GetLimitStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLimitStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).GetLimitStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_GetLimitStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).GetLimitStatus(ctx, req.(*GetLimitStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_ResetLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).ResetLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_ResetLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).ResetLimit(ctx, req.(*ResetLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_Refund_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).Refund(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_Refund_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).Refund(ctx, req.(*RefundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_AcquireConcurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AcquireConcurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).AcquireConcurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_AcquireConcurrency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).AcquireConcurrency(ctx, req.(*AcquireConcurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_ReleaseConcurrency_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReleaseConcurrencyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).ReleaseConcurrency(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_ReleaseConcurrency_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).ReleaseConcurrency(ctx, req.(*ReleaseConcurrencyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_LeaseQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LeaseQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).LeaseQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_LeaseQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).LeaseQuota(ctx, req.(*LeaseQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RateLimiter_ReturnQuota_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReturnQuotaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RateLimiterServer).ReturnQuota(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RateLimiter_ReturnQuota_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RateLimiterServer).ReturnQuota(ctx, req.(*ReturnQuotaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RateLimiter_ServiceDesc is the grpc.ServiceDesc for RateLimiter service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -119,7 +491,50 @@ var RateLimiter_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CheckLimit",
 			Handler:    _RateLimiter_CheckLimit_Handler,
 		},
+		{
+			MethodName: "CheckLimits",
+			Handler:    _RateLimiter_CheckLimits_Handler,
+		},
+		{
+			MethodName: "BatchCheckLimit",
+			Handler:    _RateLimiter_BatchCheckLimit_Handler,
+		},
+		{
+			MethodName: "GetLimitStatus",
+			Handler:    _RateLimiter_GetLimitStatus_Handler,
+		},
+		{
+			MethodName: "ResetLimit",
+			Handler:    _RateLimiter_ResetLimit_Handler,
+		},
+		{
+			MethodName: "Refund",
+			Handler:    _RateLimiter_Refund_Handler,
+		},
+		{
+			MethodName: "AcquireConcurrency",
+			Handler:    _RateLimiter_AcquireConcurrency_Handler,
+		},
+		{
+			MethodName: "ReleaseConcurrency",
+			Handler:    _RateLimiter_ReleaseConcurrency_Handler,
+		},
+		{
+			MethodName: "LeaseQuota",
+			Handler:    _RateLimiter_LeaseQuota_Handler,
+		},
+		{
+			MethodName: "ReturnQuota",
+			Handler:    _RateLimiter_ReturnQuota_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamCheckLimit",
+			Handler:       _RateLimiter_StreamCheckLimit_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/ratelimiter.proto",
 }
@@ -0,0 +1,178 @@
+// Package quotaclient answers rate limit checks from permits leased in batches
+// with LeaseQuota, so only one request in every batch goes to the server.
+package quotaclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	pb "github.com/cynkin/rlaas/proto"
+)
+
+// How long returning unused permits from an expired lease may take. It runs
+// in the background so it never holds up Allow.
+const returnTimeout = 2 * time.Second
+
+// Limiter spends leased permits for one client and rule. Safe for concurrent use.
+type Limiter struct {
+	client   pb.RateLimiterClient
+	clientID string
+	ruleID   string
+	batch    int32         // permits asked for per lease
+	ttl      time.Duration // how long a lease may be spent from
+
+	mu           sync.Mutex
+	leaseID      string
+	left         int32         // permits of the current lease not spent yet
+	expiresAt    time.Time     // current lease is void after this
+	blockedUntil time.Time     // don't ask the server again before this
+	leasing      chan struct{} // closed once the lease request in flight is answered, nil without one
+}
+
+// New creates a limiter leasing batch permits at a time, each lease spendable
+// for ttl. A ttl of 0 uses the server default.
+func New(client pb.RateLimiterClient, clientID, ruleID string, batch int, ttl time.Duration) (*Limiter, error) {
+	switch {
+	case client == nil:
+		return nil, errors.New("client is required")
+	case ruleID == "":
+		return nil, errors.New("rule ID is required")
+	case batch <= 0 || batch > math.MaxInt32:
+		return nil, fmt.Errorf("batch must be between 1 and %d, got %d", math.MaxInt32, batch)
+	case ttl < 0:
+		return nil, fmt.Errorf("ttl must not be negative, got %v", ttl)
+	}
+
+	return &Limiter{
+		client:   client,
+		clientID: clientID,
+		ruleID:   ruleID,
+		batch:    int32(batch),
+		ttl:      ttl,
+	}, nil
+}
+
+// Allow spends one permit, leasing a new batch when the current one is used up
+// or expired. Blocked callers are answered locally until the server's
+// retry_after has passed.
+//
+// Only one lease is asked for at a time. Callers arriving meanwhile wait for
+// it rather than lease a batch each, but the lock isn't held over the RPC, so
+// permits already leased keep being spent.
+func (l *Limiter) Allow(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	for {
+		now := time.Now()
+		if l.left > 0 && now.Before(l.expiresAt) {
+			l.left--
+			l.mu.Unlock()
+			return true, nil
+		}
+
+		// Lease is spent or void, give back what wasn't used before taking another
+		l.returnLease()
+
+		if now.Before(l.blockedUntil) {
+			l.mu.Unlock()
+			return false, nil
+		}
+
+		leasing := l.leasing
+		if leasing == nil {
+			break
+		}
+		l.mu.Unlock()
+		select {
+		case <-leasing:
+		case <-ctx.Done():
+			return false, ctx.Err()
+		}
+		l.mu.Lock()
+	}
+
+	done := make(chan struct{})
+	l.leasing = done
+	l.mu.Unlock()
+
+	resp, err := l.client.LeaseQuota(ctx, &pb.LeaseQuotaRequest{
+		ClientId: l.clientID,
+		RuleId:   l.ruleID,
+		Permits:  l.batch,
+		TtlMs:    l.ttl.Milliseconds(),
+	})
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.leasing = nil
+	close(done)
+
+	if err != nil {
+		return false, fmt.Errorf("lease quota: %w", err)
+	}
+
+	if resp.Granted == 0 {
+		l.blockedUntil = time.Now().Add(time.Duration(resp.RetryAfterMs) * time.Millisecond)
+		return false, nil
+	}
+
+	l.leaseID = resp.LeaseId
+	l.left = resp.Granted - 1 // this call spends the first one
+	l.expiresAt = time.UnixMilli(resp.ExpiresAtMs)
+	return true, nil
+}
+
+// Close returns any unspent permits to the server. Stop calling Allow first, a
+// lease it is still waiting for isn't returned.
+func (l *Limiter) Close(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.leaseID == "" {
+		return nil
+	}
+
+	leaseID, unused := l.leaseID, l.left
+	l.leaseID, l.left = "", 0
+
+	_, err := l.client.ReturnQuota(ctx, &pb.ReturnQuotaRequest{
+		ClientId: l.clientID,
+		RuleId:   l.ruleID,
+		LeaseId:  leaseID,
+		Unused:   unused,
+	})
+	if err != nil {
+		return fmt.Errorf("return quota: %w", err)
+	}
+	return nil
+}
+
+// returnLease closes out the current lease in the background. Must be called
+// with mu held.
+func (l *Limiter) returnLease() {
+	if l.leaseID == "" {
+		return
+	}
+
+	req := &pb.ReturnQuotaRequest{
+		ClientId: l.clientID,
+		RuleId:   l.ruleID,
+		LeaseId:  l.leaseID,
+		Unused:   l.left,
+	}
+	l.leaseID, l.left = "", 0
+
+	// Nothing to give back, the server drops the lease record on its own
+	if req.Unused == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), returnTimeout)
+		defer cancel()
+		l.client.ReturnQuota(ctx, req)
+	}()
+}
@@ -0,0 +1,227 @@
+package quotaclient
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/cynkin/rlaas/proto"
+	"google.golang.org/grpc"
+)
+
+// fakeServer hands out leases from a fixed pool of permits
+type fakeServer struct {
+	pb.RateLimiterClient // only the quota calls are used
+
+	mu         sync.Mutex
+	available  int32
+	retryAfter time.Duration
+	leases     int           // LeaseQuota calls
+	returned   chan int32    // unused permits sent back, buffered
+	hold       chan struct{} // LeaseQuota waits on this when set
+	ready      chan struct{} // closed when a held LeaseQuota has started
+}
+
+func newFakeServer(available int32) *fakeServer {
+	return &fakeServer{available: available, returned: make(chan int32, 10)}
+}
+
+func (f *fakeServer) LeaseQuota(ctx context.Context, in *pb.LeaseQuotaRequest, _ ...grpc.CallOption) (*pb.LeaseQuotaResponse, error) {
+	if f.hold != nil {
+		close(f.ready)
+		<-f.hold
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.leases++
+
+	if f.available == 0 {
+		return &pb.LeaseQuotaResponse{RetryAfterMs: f.retryAfter.Milliseconds()}, nil
+	}
+	granted := min(in.Permits, f.available)
+	f.available -= granted
+	return &pb.LeaseQuotaResponse{
+		Granted:     granted,
+		LeaseId:     "lease",
+		ExpiresAtMs: time.Now().Add(time.Minute).UnixMilli(),
+	}, nil
+}
+
+func (f *fakeServer) ReturnQuota(ctx context.Context, in *pb.ReturnQuotaRequest, _ ...grpc.CallOption) (*pb.ReturnQuotaResponse, error) {
+	f.returned <- in.Unused
+	return &pb.ReturnQuotaResponse{Returned: in.Unused}, nil
+}
+
+func (f *fakeServer) leaseCalls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.leases
+}
+
+func TestNew(t *testing.T) {
+	server := newFakeServer(0)
+
+	tests := []struct {
+		name    string
+		client  pb.RateLimiterClient
+		ruleID  string
+		batch   int
+		ttl     time.Duration
+		wantErr bool
+	}{
+		{name: "valid", client: server, ruleID: "api", batch: 10},
+		{name: "valid with ttl", client: server, ruleID: "api", batch: 1, ttl: time.Second},
+		{name: "no client", ruleID: "api", batch: 10, wantErr: true},
+		{name: "no rule", client: server, batch: 10, wantErr: true},
+		{name: "zero batch", client: server, ruleID: "api", wantErr: true},
+		{name: "negative batch", client: server, ruleID: "api", batch: -1, wantErr: true},
+		{name: "batch over int32", client: server, ruleID: "api", batch: 1 << 31, wantErr: true},
+		{name: "negative ttl", client: server, ruleID: "api", batch: 10, ttl: -time.Second, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := New(tt.client, "client", tt.ruleID, tt.batch, tt.ttl)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && l == nil {
+				t.Fatal("New returned neither a limiter nor an error")
+			}
+		})
+	}
+}
+
+func TestAllowSpendsBatch(t *testing.T) {
+	server := newFakeServer(7)
+	server.retryAfter = time.Hour
+	l, err := New(server, "client", "api", 5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// A batch of 5, then the 2 left, then blocked
+	for i := range 7 {
+		if ok, err := l.Allow(ctx); err != nil || !ok {
+			t.Fatalf("call %d: %v, %v", i, ok, err)
+		}
+	}
+	if ok, err := l.Allow(ctx); err != nil || ok {
+		t.Fatalf("allowed past the server's permits: %v, %v", ok, err)
+	}
+	if got := server.leaseCalls(); got != 3 {
+		t.Errorf("%d leases, want 3", got)
+	}
+
+	// Blocked until retry_after, without asking the server
+	if ok, _ := l.Allow(ctx); ok {
+		t.Fatal("allowed while blocked")
+	}
+	if got := server.leaseCalls(); got != 3 {
+		t.Errorf("%d leases after blocking, want 3", got)
+	}
+}
+
+func TestAllowConcurrentLeasesOnce(t *testing.T) {
+	server := newFakeServer(100)
+	server.hold = make(chan struct{})
+	server.ready = make(chan struct{})
+	l, err := New(server, "client", "api", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	allowed := make(chan bool, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := l.Allow(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			allowed <- ok
+		}()
+	}
+
+	// While the lease is in flight the limiter isn't locked: others give up
+	// on their own context rather than hang behind the RPC
+	<-server.ready
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Allow(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Allow during a lease = %v, want the context's deadline", err)
+	}
+
+	close(server.hold)
+	wg.Wait()
+	close(allowed)
+
+	for ok := range allowed {
+		if !ok {
+			t.Error("a caller was refused with permits leased")
+		}
+	}
+	if got := server.leaseCalls(); got != 1 {
+		t.Errorf("%d leases for one batch, want 1", got)
+	}
+}
+
+func TestAllowLeaseError(t *testing.T) {
+	l, err := New(errServer{}, "client", "api", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := l.Allow(context.Background()); ok || err == nil {
+		t.Fatalf("Allow = %v, %v, want an error", ok, err)
+	}
+
+	// The failed lease isn't left in flight
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := l.Allow(ctx); errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("second Allow waited on the failed lease")
+	}
+}
+
+type errServer struct {
+	pb.RateLimiterClient
+}
+
+func (errServer) LeaseQuota(context.Context, *pb.LeaseQuotaRequest, ...grpc.CallOption) (*pb.LeaseQuotaResponse, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestClose(t *testing.T) {
+	server := newFakeServer(10)
+	l, err := New(server, "client", "api", 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	for range 3 {
+		l.Allow(ctx)
+	}
+	if err := l.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-server.returned; got != 7 {
+		t.Errorf("returned %d, want 7", got)
+	}
+
+	// Nothing left to return
+	if err := l.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-server.returned:
+		t.Errorf("second Close returned %d more", got)
+	default:
+	}
+}
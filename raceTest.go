@@ -33,11 +33,11 @@ func runRaceTest() {
 		wg.Add(1)
 		go func(id int) {
 			defer wg.Done()
-			allowed, _, err := fw.Allow(ctx, "race-client")
+			res, err := fw.Allow(ctx, "race-client", 1)
 			if err != nil {
 				return
 			}
-			if allowed {
+			if res.Allowed {
 				mu.Lock()
 				totalAllowed++
 				mu.Unlock()
@@ -40,19 +40,19 @@ func startServer(port string, redisAddr string) {
 			clientID = "default"
 		}
 
-		allowed, remaining, err := fw.Allow(r.Context(), clientID)
+		res, err := fw.Allow(r.Context(), clientID, 1)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		if !allowed {
+		if !res.Allowed {
 			w.WriteHeader(http.StatusTooManyRequests)
 		}
 		json.NewEncoder(w).Encode(Response{
-			Allowed:   allowed,
-			Remaining: remaining,
+			Allowed:   res.Allowed,
+			Remaining: res.Remaining,
 			ClientID:  clientID,
 			Server:    hostname,
 		})
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/redis/go-redis/v9"
+)
+
+// BatchCheck is one check in a batch. Unlike MultiCheck, checks in a batch are
+// independent: each consumes or not on its own.
+type BatchCheck struct {
+	Limiter   limiter.Limiter
+	ClientKey string
+	Cost      int
+}
+
+// batchRule is implemented by limiters whose script can be queued on a
+// pipeline. queue runs the script on c, or queues it when c is a pipeline, and
+// returns a func that reads the result once it is in.
+type batchRule interface {
+	queue(ctx context.Context, c redis.Scripter, clientID string, cost int) func() (limiter.Result, error)
+}
+
+type AtomicBatch struct {
+	client redis.UniversalClient // nil runs every check on its own
+}
+
+func NewAtomicBatch(client redis.UniversalClient) *AtomicBatch {
+	return &AtomicBatch{client: client}
+}
+
+// Allow runs the checks in one pipelined round trip, returning a result and an
+// error per check. Limiters that can't be pipelined, like the in-memory ones,
+// are checked one by one.
+func (b *AtomicBatch) Allow(ctx context.Context, checks []BatchCheck) ([]limiter.Result, []error) {
+	results := make([]limiter.Result, len(checks))
+	errs := make([]error, len(checks))
+
+	var pipe redis.Pipeliner
+	reads := make([]func() (limiter.Result, error), len(checks))
+	for i, check := range checks {
+		rule, ok := check.Limiter.(batchRule)
+		if !ok || b.client == nil {
+			results[i], errs[i] = check.Limiter.Allow(ctx, check.ClientKey, check.Cost)
+			continue
+		}
+		if pipe == nil {
+			pipe = b.client.Pipeline()
+		}
+		reads[i] = rule.queue(ctx, pipe, check.ClientKey, check.Cost)
+	}
+	if pipe == nil {
+		return results, errs
+	}
+
+	// Errors come back per command, read below
+	pipe.Exec(ctx)
+
+	for i, read := range reads {
+		if read == nil {
+			continue
+		}
+		results[i], errs[i] = read()
+
+		// The server doesn't have the script cached (restart, failover, SCRIPT
+		// FLUSH), so nothing ran. Allow falls back to sending the script itself.
+		if redis.HasErrorPrefix(errs[i], "NOSCRIPT") {
+			results[i], errs[i] = checks[i].Limiter.Allow(ctx, checks[i].ClientKey, checks[i].Cost)
+		}
+	}
+	return results, errs
+}
@@ -0,0 +1,39 @@
+package store
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Calendar periods a fixed window rule can be aligned to instead of window_secs
+var CalendarPeriods = []string{"day", "week", "month"}
+
+func IsCalendarPeriod(period string) bool {
+	for _, p := range CalendarPeriods {
+		if p == period {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadLocation does a filesystem read per call, so keep the results around
+var locationCache sync.Map // timezone name -> *time.Location
+
+// LoadTimezone resolves an IANA timezone name, empty meaning UTC
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if loc, ok := locationCache.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", name, err)
+	}
+	locationCache.Store(name, loc)
+	return loc, nil
+}
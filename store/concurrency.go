@@ -0,0 +1,140 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Leases live in a ZSET scored by their expiry, so a caller that crashes
+// without releasing only holds its slot until the lease TTL runs out.
+var acquireConcurrencyScript = redis.NewScript(`
+	local key = KEYS[1]
+	local limit = tonumber(ARGV[1])
+	local now = tonumber(ARGV[2])
+	local expires_at = tonumber(ARGV[3])
+	local lease_id = ARGV[4]
+
+	-- Drop leases whose holders never released them
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now)
+
+	local count = redis.call('ZCARD', key)
+	if count >= limit then
+		-- Full: the earliest expiring lease is the latest a slot frees up
+		local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+		return {0, 0, tonumber(oldest[2]) - now}
+	end
+
+	redis.call('ZADD', key, expires_at, lease_id)
+
+	-- Keep the set alive as long as its longest lease
+	local longest = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+	redis.call('PEXPIREAT', key, math.ceil(tonumber(longest[2]) / 1000))
+
+	return {1, limit - count - 1, 0}
+`)
+
+var releaseConcurrencyScript = redis.NewScript(`
+	local key = KEYS[1]
+	local lease_id = ARGV[1]
+	local now = tonumber(ARGV[2])
+
+	local expires_at = tonumber(redis.call('ZSCORE', key, lease_id))
+	if expires_at == nil then
+		return 0
+	end
+
+	redis.call('ZREM', key, lease_id)
+
+	-- A lapsed lease has already given its slot away
+	if expires_at <= now then
+		return 0
+	end
+	return 1
+`)
+
+type AtomicConcurrencyLimiter struct {
+	client   redis.UniversalClient
+	limit    int           // max leases held at once
+	leaseTTL time.Duration // how long a lease lives without a release
+}
+
+func NewAtomicConcurrency(client redis.UniversalClient, limit int, leaseTTL time.Duration) *AtomicConcurrencyLimiter {
+	return &AtomicConcurrencyLimiter{
+		client:   client,
+		limit:    limit,
+		leaseTTL: leaseTTL,
+	}
+}
+
+// Lease is a held concurrency slot
+type Lease struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+func newLeaseID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Acquire takes a slot for clientID if one is free. When it is not, the returned
+// duration is how long until the oldest lease expires on its own.
+func (a *AtomicConcurrencyLimiter) Acquire(ctx context.Context, clientID string) (bool, Lease, int, time.Duration, error) {
+	key := fmt.Sprintf("rate:atomic:inflight:%s", hashTag(clientID))
+
+	leaseID, err := newLeaseID()
+	if err != nil {
+		return false, Lease{}, 0, 0, fmt.Errorf("lease id: %w", err)
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(a.leaseTTL)
+
+	result, err := acquireConcurrencyScript.Run(
+		ctx,
+		a.client,
+		[]string{key},
+		a.limit,
+		now.UnixMicro(),
+		expiresAt.UnixMicro(),
+		leaseID,
+	).Int64Slice()
+
+	if err != nil {
+		return false, Lease{}, 0, 0, fmt.Errorf("lua script error: %w", err)
+	}
+
+	if result[0] != 1 {
+		return false, Lease{}, 0, time.Duration(result[2]) * time.Microsecond, nil
+	}
+
+	return true, Lease{ID: leaseID, ExpiresAt: expiresAt}, int(result[1]), 0, nil
+}
+
+// Release frees the slot held by leaseID. It reports false if the lease had
+// already expired or been released.
+func (a *AtomicConcurrencyLimiter) Release(ctx context.Context, clientID string, leaseID string) (bool, error) {
+	key := fmt.Sprintf("rate:atomic:inflight:%s", hashTag(clientID))
+
+	released, err := releaseConcurrencyScript.Run(
+		ctx,
+		a.client,
+		[]string{key},
+		leaseID,
+		time.Now().UnixMicro(),
+	).Int()
+
+	if err != nil {
+		return false, fmt.Errorf("lua script error: %w", err)
+	}
+
+	return released == 1, nil
+}
@@ -0,0 +1,81 @@
+package store
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Descriptors are the key/value pairs a check carries, like ip, path, method or
+// tenant. The check's client ID is always there under "client_id".
+type Descriptors map[string]string
+
+// Values a rule's match can use besides an exact value
+const (
+	DescriptorAny    = "*" // the descriptor must be present, any value
+	DescriptorAbsent = ""  // the descriptor must not be present
+)
+
+// Matches reports whether the rule applies to a check with these descriptors.
+// Every match entry must hold, and every key_by descriptor must be present so
+// the counter key can be built.
+func (r Rule) Matches(d Descriptors) bool {
+	for key, want := range r.Match {
+		got, ok := d[key]
+		switch want {
+		case DescriptorAny:
+			if !ok {
+				return false
+			}
+		case DescriptorAbsent:
+			if ok {
+				return false
+			}
+		default:
+			if got != want {
+				return false
+			}
+		}
+	}
+	for _, key := range r.KeyBy {
+		if _, ok := d[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// UsesDescriptors reports whether the rule needs more than the client ID to
+// decide if it applies or to key its counters
+func (r Rule) UsesDescriptors() bool {
+	if len(r.Match) > 0 {
+		return true
+	}
+	for _, key := range r.KeyBy {
+		if key != "client_id" {
+			return true
+		}
+	}
+	return false
+}
+
+// CounterKey is the key the rule's counters live under for a check. Without
+// key_by that is the client's key; with it, the listed descriptor values take
+// the client's place, so key_by ["ip"] counts per IP across all clients.
+//
+// Keys and values are query-escaped, or a value holding "," or "=" could pass
+// for another set of values and share its counter. Escaping the braces keeps
+// the hash tag whole too.
+func (r Rule) CounterKey(requested string, d Descriptors) string {
+	// Same key as no key_by at all, so multi-rule checks stay in one slot
+	if len(r.KeyBy) == 0 || slices.Equal(r.KeyBy, []string{"client_id"}) {
+		return ClientKey(r.CounterID(requested), d["client_id"])
+	}
+
+	parts := make([]string, len(r.KeyBy))
+	for i, key := range r.KeyBy {
+		parts[i] = url.QueryEscape(key) + "=" + url.QueryEscape(d[key])
+	}
+	// Always holds a "=", which escaped client IDs never do
+	return tagKey(r.CounterID(requested), strings.Join(parts, ","))
+}
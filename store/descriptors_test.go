@@ -0,0 +1,190 @@
+package store
+
+import "testing"
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+		d    Descriptors
+		want bool
+	}{
+		{
+			name: "no match or key_by applies to everything",
+			d:    Descriptors{},
+			want: true,
+		},
+		{
+			name: "exact value",
+			rule: Rule{Match: map[string]string{"method": "POST"}},
+			d:    Descriptors{"method": "POST"},
+			want: true,
+		},
+		{
+			name: "other value",
+			rule: Rule{Match: map[string]string{"method": "POST"}},
+			d:    Descriptors{"method": "GET"},
+			want: false,
+		},
+		{
+			name: "exact value missing",
+			rule: Rule{Match: map[string]string{"method": "POST"}},
+			d:    Descriptors{},
+			want: false,
+		},
+		{
+			name: "any value present",
+			rule: Rule{Match: map[string]string{"tenant": DescriptorAny}},
+			d:    Descriptors{"tenant": "acme"},
+			want: true,
+		},
+		{
+			name: "any value, present but empty",
+			rule: Rule{Match: map[string]string{"tenant": DescriptorAny}},
+			d:    Descriptors{"tenant": ""},
+			want: true,
+		},
+		{
+			name: "any value missing",
+			rule: Rule{Match: map[string]string{"tenant": DescriptorAny}},
+			d:    Descriptors{},
+			want: false,
+		},
+		{
+			name: "absent and missing",
+			rule: Rule{Match: map[string]string{"tenant": DescriptorAbsent}},
+			d:    Descriptors{"ip": "10.0.0.1"},
+			want: true,
+		},
+		{
+			name: "absent but present",
+			rule: Rule{Match: map[string]string{"tenant": DescriptorAbsent}},
+			d:    Descriptors{"tenant": "acme"},
+			want: false,
+		},
+		{
+			// Present with an empty value is still present
+			name: "absent but present and empty",
+			rule: Rule{Match: map[string]string{"tenant": DescriptorAbsent}},
+			d:    Descriptors{"tenant": ""},
+			want: false,
+		},
+		{
+			name: "every match entry has to hold",
+			rule: Rule{Match: map[string]string{"method": "POST", "path": "/login"}},
+			d:    Descriptors{"method": "POST", "path": "/logout"},
+			want: false,
+		},
+		{
+			name: "key_by descriptor present",
+			rule: Rule{KeyBy: []string{"ip"}},
+			d:    Descriptors{"ip": "10.0.0.1"},
+			want: true,
+		},
+		{
+			name: "key_by descriptor missing",
+			rule: Rule{KeyBy: []string{"ip"}},
+			d:    Descriptors{"client_id": "acme"},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Matches(tt.d); got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.d, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleCounterKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      Rule
+		requested string
+		d         Descriptors
+		want      string
+	}{
+		{
+			name: "no key_by is the client's key",
+			rule: Rule{RuleID: "login"},
+			d:    Descriptors{"client_id": "acme", "ip": "10.0.0.1"},
+			want: "login:{acme}",
+		},
+		{
+			name: "key_by client_id alone is the same key",
+			rule: Rule{RuleID: "login", KeyBy: []string{"client_id"}},
+			d:    Descriptors{"client_id": "acme"},
+			want: "login:{acme}",
+		},
+		{
+			name: "anonymous client",
+			rule: Rule{RuleID: "login"},
+			d:    Descriptors{},
+			want: "login:{_}",
+		},
+		{
+			name: "a client named _ doesn't share the anonymous counter",
+			rule: Rule{RuleID: "login"},
+			d:    Descriptors{"client_id": "_"},
+			want: "login:{%5F}",
+		},
+		{
+			name: "braces in client IDs are escaped",
+			rule: Rule{RuleID: "login"},
+			d:    Descriptors{"client_id": "a}b{c"},
+			want: "login:{a%7Db%7Bc}",
+		},
+		{
+			name: "key_by takes the client's place",
+			rule: Rule{RuleID: "login", KeyBy: []string{"ip"}},
+			d:    Descriptors{"client_id": "acme", "ip": "10.0.0.1"},
+			want: "login:{ip=10.0.0.1}",
+		},
+		{
+			name: "several key_by in the rule's order",
+			rule: Rule{RuleID: "login", KeyBy: []string{"tenant", "ip"}},
+			d:    Descriptors{"ip": "10.0.0.1", "tenant": "acme"},
+			want: "login:{tenant=acme,ip=10.0.0.1}",
+		},
+		{
+			name: "separators in values are escaped",
+			rule: Rule{RuleID: "login", KeyBy: []string{"a", "b"}},
+			d:    Descriptors{"a": "x,b=y", "b": "z"},
+			want: "login:{a=x%2Cb%3Dy,b=z}",
+		},
+		{
+			name: "braces in values are escaped",
+			rule: Rule{RuleID: "login", KeyBy: []string{"path"}},
+			d:    Descriptors{"path": "/users/{id}"},
+			want: "login:{path=%2Fusers%2F%7Bid%7D}",
+		},
+		{
+			name:      "pattern rules count under the requested rule ID",
+			rule:      Rule{RuleID: "api.*", KeyBy: []string{"ip"}},
+			requested: "api.users",
+			d:         Descriptors{"ip": "10.0.0.1"},
+			want:      "api.users:{ip=10.0.0.1}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.CounterKey(tt.requested, tt.d); got != tt.want {
+				t.Errorf("CounterKey = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRuleCounterKeyDistinct(t *testing.T) {
+	rule := Rule{RuleID: "login", KeyBy: []string{"a", "b"}}
+
+	// Each pair joined without escaping would be "a=x,b=y,b=z"
+	first := rule.CounterKey("", Descriptors{"a": "x,b=y", "b": "z"})
+	second := rule.CounterKey("", Descriptors{"a": "x", "b": "y,b=z"})
+	if first == second {
+		t.Errorf("different values share the counter %q", first)
+	}
+}
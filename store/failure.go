@@ -0,0 +1,19 @@
+package store
+
+// Failure modes decide what a check returns when the backend errors
+const (
+	FailOpen          = "open"           // allow, the rule is skipped
+	FailClosed        = "closed"         // block, nothing gets through
+	FailLocalFallback = "local-fallback" // limit with this instance's in-memory counters
+)
+
+var FailureModes = []string{FailOpen, FailClosed, FailLocalFallback}
+
+func IsFailureMode(mode string) bool {
+	for _, m := range FailureModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
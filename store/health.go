@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Consecutive good pings needed before Redis is trusted again, so a flapping
+// connection doesn't bounce callers between counters
+const healthyAfter = 3
+
+// RedisHealth tracks whether Redis is reachable. Checks skip Redis while it is
+// down instead of waiting on timeouts, and go back to it once pings succeed.
+type RedisHealth struct {
+	client   redis.UniversalClient
+	interval time.Duration
+	down     atomic.Bool
+	good     atomic.Int32 // pings in a row that succeeded
+}
+
+func NewRedisHealth(client redis.UniversalClient, interval time.Duration) *RedisHealth {
+	return &RedisHealth{
+		client:   client,
+		interval: interval,
+	}
+}
+
+func (h *RedisHealth) Healthy() bool {
+	return !h.down.Load()
+}
+
+// IsUnavailable reports whether err means Redis couldn't be reached or can't
+// serve at all (network errors, timeouts, an exhausted pool), as opposed to one
+// command failing on its key or script, like WRONGTYPE, OOM or a Lua error.
+// Only the former says anything about Redis' health.
+func IsUnavailable(err error) bool {
+	var netErr net.Error // timeouts, context.DeadlineExceeded included
+	if errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, redis.ErrClosed) ||
+		errors.Is(err, redis.ErrPoolTimeout) ||
+		errors.Is(err, redis.ErrPoolExhausted) {
+		return true
+	}
+
+	// Replies from a node that isn't serving anything right now
+	for _, prefix := range []string{"LOADING", "MASTERDOWN", "CLUSTERDOWN"} {
+		if redis.HasErrorPrefix(err, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportFailure marks Redis down right away, without waiting for the next ping
+func (h *RedisHealth) ReportFailure(err error) {
+	h.good.Store(0)
+	if !h.down.Swap(true) {
+		fmt.Printf("✗ Redis marked unhealthy: %v\n", err)
+	}
+}
+
+// Run pings Redis every interval until ctx is done
+func (h *RedisHealth) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		pingCtx, cancel := context.WithTimeout(ctx, h.interval)
+		err := h.client.Ping(pingCtx).Err()
+		cancel()
+
+		if err != nil {
+			h.ReportFailure(err)
+			continue
+		}
+
+		if h.good.Add(1) >= healthyAfter && h.down.Swap(false) {
+			fmt.Println("✓ Redis healthy again, resuming shared counters")
+		}
+	}
+}
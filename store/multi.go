@@ -0,0 +1,315 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/redis/go-redis/v9"
+)
+
+// multiRuleScript checks every rule first and only consumes from them if all
+// allow, so a request blocked by one rule never eats into the others.
+//
+// Each rule takes 2 KEYS (the second repeats the first when unused) and 4 ARGV:
+// algorithm followed by 3 algorithm-specific parameters. Key layouts and maths
+// match the single-rule scripts so both paths share the same counters.
+var multiRuleScript = redis.NewScript(`
+	local now = tonumber(ARGV[1])
+	local cost = tonumber(ARGV[2])
+	local n = tonumber(ARGV[3])
+
+	-- Microseconds until units drain or refill at rate per second
+	local function wait_us(units, rate)
+		if units <= 0 or rate <= 0 then return 0 end
+		return math.ceil(units / rate * 1000000)
+	end
+
+	local all_allowed = 1
+	local decisions = {}
+	local commits = {}
+
+	for i = 1, n do
+		local base = 3 + (i - 1) * 4
+		local algorithm = ARGV[base + 1]
+		local p1 = tonumber(ARGV[base + 2])
+		local p2 = tonumber(ARGV[base + 3])
+		local p3 = tonumber(ARGV[base + 4])
+		local k1 = KEYS[(i - 1) * 2 + 1]
+		local k2 = KEYS[(i - 1) * 2 + 2]
+
+		-- *_now is reported if nothing gets consumed, *_after if it does.
+		-- reset is microseconds until the client is back to a full allowance.
+		local allowed, retry_us = 0, 0
+		local remaining_now, remaining_after = 0, 0
+		local reset_now, reset_after = 0, 0
+		local commit = nil
+
+		if algorithm == 'fixed_window' then
+			-- p1 = limit, p2 = seconds until the window ends, p3 = window end
+			local count = tonumber(redis.call('GET', k1)) or 0
+			remaining_now = p1 - count
+			reset_now, reset_after = p3 - now, p3 - now
+			if count + cost <= p1 then
+				allowed, remaining_after = 1, p1 - count - cost
+				commit = function()
+					if redis.call('INCRBY', k1, cost) == cost then
+						redis.call('EXPIRE', k1, p2)
+					end
+				end
+			else
+				retry_us = p3 - now
+			end
+
+		elseif algorithm == 'sliding_window' then
+			-- p1 = limit, p2 = window start, p3 = ttl seconds
+			local window_us = now - p2
+			redis.call('ZREMRANGEBYSCORE', k1, '0', p2)
+			local count = redis.call('ZCARD', k1)
+			remaining_now = p1 - count
+			local newest = redis.call('ZRANGE', k1, -1, -1, 'WITHSCORES')
+			if newest[2] then reset_now = tonumber(newest[2]) + window_us - now end
+			reset_after = window_us
+			if count + cost <= p1 then
+				allowed, remaining_after = 1, p1 - count - cost
+				commit = function()
+					-- Same members as slidingWindowScript, see there
+					local seq = redis.call('ZCOUNT', k1, now, now)
+					for j = 1, cost do
+						redis.call('ZADD', k1, now, string.format('%s:%06d', ARGV[1], seq + j))
+					end
+					redis.call('EXPIRE', k1, p3)
+				end
+			else
+				-- Fits once the oldest (count + cost - limit) entries have left the window
+				local needed = redis.call('ZRANGE', k1, count + cost - p1 - 1, count + cost - p1 - 1, 'WITHSCORES')
+				retry_us = window_us
+				if needed[2] then retry_us = tonumber(needed[2]) + window_us - now end
+			end
+
+		elseif algorithm == 'sliding_window_counter' then
+			-- k1 = current bucket, k2 = previous bucket; p1 = limit, p2 = window seconds, p3 = previous weight
+			local window_us = p2 * 1000000
+			local elapsed_us = (1 - p3) * window_us
+			local until_end = window_us - elapsed_us
+			local previous = tonumber(redis.call('GET', k2)) or 0
+			local current = tonumber(redis.call('GET', k1)) or 0
+			local estimated = previous * p3 + current
+			remaining_now = math.floor(p1 - estimated)
+			if current > 0 then
+				reset_now = until_end + window_us
+			elseif previous > 0 then
+				reset_now = until_end
+			end
+			reset_after = until_end + window_us
+			if estimated + cost <= p1 then
+				allowed, remaining_after = 1, math.floor(p1 - estimated - cost)
+				commit = function()
+					if redis.call('INCRBY', k1, cost) == cost then
+						redis.call('EXPIRE', k1, p2 * 2)
+					end
+				end
+			else
+				-- Solve for when the decaying previous bucket leaves room
+				local budget = p1 - cost - current
+				if budget >= 0 and previous > 0 then
+					retry_us = math.ceil((1 - budget / previous) * window_us - elapsed_us)
+				elseif current <= 0 or p1 - cost < 0 then
+					retry_us = math.ceil(until_end)
+				else
+					retry_us = math.ceil(until_end + math.max(0, 1 - (p1 - cost) / current) * window_us)
+				end
+			end
+
+		elseif algorithm == 'token_bucket' then
+			-- k1 = tokens, k2 = last refill; p1 = capacity, p2 = refill rate, p3 = ttl seconds
+			local tokens = tonumber(redis.call('GET', k1)) or p1
+			local last_refill = tonumber(redis.call('GET', k2)) or now
+			tokens = math.min(p1, tokens + (now - last_refill) / 1000000.0 * p2)
+			remaining_now = math.floor(tokens)
+			reset_now = wait_us(p1 - tokens, p2)
+			reset_after = wait_us(p1 - tokens + cost, p2)
+			if tokens >= cost then
+				allowed, remaining_after = 1, math.floor(tokens - cost)
+				commit = function()
+					redis.call('SET', k1, tostring(tokens - cost), 'EX', p3)
+					redis.call('SET', k2, string.format('%d', now), 'EX', p3)
+				end
+			else
+				retry_us = wait_us(cost - tokens, p2)
+			end
+
+		elseif algorithm == 'leaky_bucket' then
+			-- p1 = capacity, p2 = leak rate, p3 = ttl seconds
+			local state = redis.call('HMGET', k1, 'level', 'last_leak')
+			local level = tonumber(state[1]) or 0
+			local last_leak = tonumber(state[2]) or now
+			level = math.max(0, level - math.max(0, now - last_leak) / 1000000.0 * p2)
+			remaining_now = math.max(0, math.floor(p1 - level))
+			reset_now = wait_us(level, p2)
+			reset_after = wait_us(level + cost, p2)
+			-- Same room as leakyBucketScript, an empty bucket takes any cost
+			local room = math.max(p1, cost)
+			if level + cost <= room then
+				allowed, remaining_after = 1, math.max(0, math.floor(p1 - level - cost))
+				commit = function()
+					local ttl = p3
+					if p2 > 0 then ttl = math.max(ttl, math.ceil((level + cost) / p2) + 1) end
+					redis.call('HSET', k1, 'level', tostring(level + cost), 'last_leak', string.format('%d', now))
+					redis.call('EXPIRE', k1, ttl)
+				end
+			else
+				retry_us = wait_us(level + cost - room, p2)
+			end
+
+		elseif algorithm == 'gcra' then
+			-- p1 = emission interval, p2 = burst
+			local tat = tonumber(redis.call('GET', k1))
+			if tat == nil or tat < now then tat = now end
+			local tolerance = p1 * p2
+			local new_tat = tat + p1 * cost
+			local allow_at = new_tat - tolerance
+			remaining_now = math.max(0, math.floor((tolerance - (tat - now)) / p1))
+			reset_now, reset_after = tat - now, new_tat - now
+			if now >= allow_at then
+				allowed, remaining_after = 1, math.floor((tolerance - (new_tat - now)) / p1)
+				commit = function()
+					redis.call('SET', k1, new_tat, 'PX', math.ceil((new_tat - now) / 1000))
+				end
+			else
+				retry_us = allow_at - now
+			end
+
+		else
+			return redis.error_reply('unsupported algorithm for multi-rule check: ' .. algorithm)
+		end
+
+		if allowed == 0 then
+			all_allowed = 0
+		else
+			table.insert(commits, commit)
+		end
+		decisions[i] = {
+			allowed, retry_us,
+			math.max(0, remaining_now), math.max(0, remaining_after),
+			reset_now, reset_after,
+		}
+	end
+
+	-- All or nothing
+	if all_allowed == 1 then
+		for _, commit in ipairs(commits) do
+			commit()
+		end
+	end
+
+	local result = {all_allowed}
+	for i = 1, n do
+		local d = decisions[i]
+		local remaining, reset = d[3], d[5]
+		if all_allowed == 1 then remaining, reset = d[4], d[6] end
+		table.insert(result, d[1])
+		table.insert(result, remaining)
+		table.insert(result, d[2])
+		table.insert(result, reset)
+	end
+	return result
+`)
+
+// ErrNotMultiRule is returned for rules whose algorithm multiRuleScript doesn't know
+var ErrNotMultiRule = errors.New("algorithm can't be part of a multi-rule check")
+
+// MultiCheck is one rule to evaluate in a multi-rule check
+type MultiCheck struct {
+	Rule      Rule
+	Limiter   limiter.Limiter // built for Rule, must be one of this package's limiters
+	ClientKey string          // same composite key CheckLimit uses for this rule
+}
+
+// multiRule is implemented by the limiters multiRuleScript knows how to evaluate.
+// multiSpec returns the keys and parameters the script needs for one rule.
+type multiRule interface {
+	multiSpec(clientID string, now time.Time) ([2]string, [4]interface{})
+}
+
+type AtomicMultiLimiter struct {
+	client redis.UniversalClient
+}
+
+func NewAtomicMulti(client redis.UniversalClient) *AtomicMultiLimiter {
+	return &AtomicMultiLimiter{client: client}
+}
+
+func (a *AtomicLimiter) multiSpec(clientID string, now time.Time) ([2]string, [4]interface{}) {
+	key := a.key(clientID, now)
+	_, windowEnd := a.Window(now)
+	return [2]string{key, key}, [4]interface{}{"fixed_window", a.limit, a.ttl(now), windowEnd.UnixMicro()}
+}
+
+func (a *AtomicSlidingWindowLimiter) multiSpec(clientID string, now time.Time) ([2]string, [4]interface{}) {
+	key := a.key(clientID)
+	windowStart := now.Add(-a.windowSize).UnixMicro()
+	return [2]string{key, key}, [4]interface{}{"sliding_window", a.limit, windowStart, int(a.windowSize.Seconds())}
+}
+
+func (a *AtomicSlidingWindowCounterLimiter) multiSpec(clientID string, now time.Time) ([2]string, [4]interface{}) {
+	currentKey, previousKey := a.keys(clientID, now)
+	elapsed := float64(now.Sub(now.Truncate(a.windowSize))) / float64(a.windowSize)
+	return [2]string{currentKey, previousKey}, [4]interface{}{"sliding_window_counter", a.limit, int(a.windowSize.Seconds()), 1 - elapsed}
+}
+
+func (a *AtomicTokenBucketLimiter) multiSpec(clientID string, now time.Time) ([2]string, [4]interface{}) {
+	tokensKey, lastRefillKey := a.keys(clientID)
+	return [2]string{tokensKey, lastRefillKey}, [4]interface{}{"token_bucket", a.capacity, a.refillRate, int(a.ttl.Seconds())}
+}
+
+func (a *AtomicLeakyBucketLimiter) multiSpec(clientID string, now time.Time) ([2]string, [4]interface{}) {
+	key := a.key(clientID)
+	return [2]string{key, key}, [4]interface{}{"leaky_bucket", a.capacity, a.leakRate, int(a.ttl.Seconds())}
+}
+
+func (a *AtomicGCRALimiter) multiSpec(clientID string, now time.Time) ([2]string, [4]interface{}) {
+	key := a.key(clientID)
+	return [2]string{key, key}, [4]interface{}{"gcra", a.emissionInterval.Microseconds(), a.burst, 0}
+}
+
+// Allow evaluates all checks in one script run. Either every rule consumes cost
+// or none of them does.
+func (a *AtomicMultiLimiter) Allow(ctx context.Context, checks []MultiCheck, cost int) (bool, []limiter.Result, error) {
+	now := time.Now()
+
+	keys := make([]string, 0, len(checks)*2)
+	args := make([]interface{}, 0, 3+len(checks)*4)
+	args = append(args, now.UnixMicro(), cost, len(checks))
+
+	for _, check := range checks {
+		mr, ok := check.Limiter.(multiRule)
+		if !ok {
+			return false, nil, fmt.Errorf("rule %q uses %s: %w",
+				check.Rule.RuleID, check.Rule.Algorithm, ErrNotMultiRule)
+		}
+		specKeys, specArgs := mr.multiSpec(check.ClientKey, now)
+		keys = append(keys, specKeys[:]...)
+		args = append(args, specArgs[:]...)
+	}
+
+	result, err := multiRuleScript.Run(ctx, a.client, keys, args...).Int64Slice()
+	if err != nil {
+		return false, nil, fmt.Errorf("lua script error: %w", err)
+	}
+
+	results := make([]limiter.Result, len(checks))
+	for i := range checks {
+		offset := 1 + i*4
+		results[i] = limiter.Result{
+			Allowed:    result[offset] == 1,
+			Remaining:  int(result[offset+1]),
+			RetryAfter: time.Duration(result[offset+2]) * time.Microsecond,
+			ResetAt:    now.Add(time.Duration(result[offset+3]) * time.Microsecond),
+		}
+	}
+
+	return result[0] == 1, results, nil
+}
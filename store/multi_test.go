@@ -0,0 +1,49 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestMultiSlidingWindowSameMicrosecond(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// Run the script directly so every call lands on the same microsecond
+	now := time.Date(2026, 1, 1, 0, 0, 0, 123456000, time.UTC)
+	check := func(cost int) bool {
+		t.Helper()
+		result, err := multiRuleScript.Run(ctx, client, []string{"sliding", "sliding"},
+			now.UnixMicro(), cost, 1,
+			"sliding_window", 5, now.Add(-time.Minute).UnixMicro(), 60).Int64Slice()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result[0] == 1
+	}
+
+	if !check(2) || !check(3) {
+		t.Fatal("checks within the limit were blocked")
+	}
+	if check(1) {
+		t.Error("check over the limit allowed")
+	}
+	if n := client.ZCard(ctx, "sliding").Val(); n != 5 {
+		t.Errorf("%d entries counted, want 5", n)
+	}
+
+	// Single-rule checks see the same entries
+	result, err := slidingWindowScript.Run(ctx, client, []string{"sliding"},
+		now.UnixMicro(), now.Add(-time.Minute).UnixMicro(), 6, 60, 1).Int64Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result[0] != 1 || client.ZCard(ctx, "sliding").Val() != 6 {
+		t.Errorf("single-rule check after multi: %v, %d entries", result, client.ZCard(ctx, "sliding").Val())
+	}
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"sort"
+	"strings"
+)
+
+// Rule IDs containing "*" are patterns: "api.v1.*" covers every rule ID starting
+// with "api.v1.", and "*" matches any run of characters anywhere in the pattern.
+
+func IsPattern(ruleID string) bool {
+	return strings.Contains(ruleID, "*")
+}
+
+// matchPattern reports whether ruleID matches pattern
+func matchPattern(pattern, ruleID string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == ruleID
+	}
+
+	// First part anchors the start, last part anchors the end
+	if !strings.HasPrefix(ruleID, parts[0]) {
+		return false
+	}
+	rest := ruleID[len(parts[0]):]
+
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return len(rest) >= len(last) && strings.HasSuffix(rest, last)
+}
+
+// sortPatterns orders patterns by precedence: the most literal characters
+// first, so the longest match wins, then alphabetically to keep ties stable.
+func sortPatterns(patterns []string) {
+	literal := func(p string) int {
+		return len(p) - strings.Count(p, "*")
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		li, lj := literal(patterns[i]), literal(patterns[j])
+		if li != lj {
+			return li > lj
+		}
+		return patterns[i] < patterns[j]
+	})
+}
+
+// CounterID is the rule ID a client's counters are kept under. Pattern rules
+// count each rule ID they match separately, like the one-per-endpoint rules
+// they stand in for.
+func (r Rule) CounterID(requested string) string {
+	if IsPattern(r.RuleID) {
+		return requested
+	}
+	return r.RuleID
+}
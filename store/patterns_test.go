@@ -0,0 +1,143 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, ruleID string
+		want            bool
+	}{
+		{pattern: "api.v1.*", ruleID: "api.v1.users", want: true},
+		{pattern: "api.v1.*", ruleID: "api.v1.", want: true}, // * matches nothing too
+		{pattern: "api.v1.*", ruleID: "api.v2.users", want: false},
+		{pattern: "api.v1.*", ruleID: "xapi.v1.users", want: false},
+		{pattern: "*.write", ruleID: "api.orders.write", want: true},
+		{pattern: "*.write", ruleID: "api.orders.write.bulk", want: false},
+		{pattern: "api.*.write", ruleID: "api.orders.write", want: true},
+		{pattern: "api.*.write", ruleID: "api.orders.read", want: false},
+		{pattern: "a*b*c", ruleID: "axxbyyc", want: true},
+		{pattern: "a*b*c", ruleID: "axxcyyb", want: false},
+		// The prefix and suffix can't share characters
+		{pattern: "ab*ba", ruleID: "aba", want: false},
+		{pattern: "ab*ba", ruleID: "abba", want: true},
+		{pattern: "*", ruleID: "anything", want: true},
+		{pattern: "*", ruleID: "", want: true},
+		{pattern: "login", ruleID: "login", want: true},
+		{pattern: "login", ruleID: "login2", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := matchPattern(tt.pattern, tt.ruleID); got != tt.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", tt.pattern, tt.ruleID, got, tt.want)
+		}
+	}
+}
+
+func TestSortPatterns(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{
+			name:     "longer literal first",
+			patterns: []string{"*", "api.*", "api.v1.*"},
+			want:     []string{"api.v1.*", "api.*", "*"},
+		},
+		{
+			name:     "stars don't count towards length",
+			patterns: []string{"a***", "ab*"},
+			want:     []string{"ab*", "a***"},
+		},
+		{
+			name:     "ties go alphabetically",
+			patterns: []string{"*.write", "api.*", "*.reads"},
+			want:     []string{"*.reads", "*.write", "api.*"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Clone(tt.patterns)
+			sortPatterns(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePrecedence(t *testing.T) {
+	rules := []Rule{
+		{RuleID: "api.v1.users", Limit: 1},
+		{RuleID: "api.v1.*", Limit: 2},
+		{RuleID: "api.*", Limit: 3},
+		{RuleID: "*.ord", Limit: 4},
+		{RuleID: "api.*", ClientID: "acme", Limit: 5},
+		{RuleID: "api.v1.users", ClientID: "acme", Limit: 6},
+		{RuleID: "api.v2.orders", Limit: 7},
+	}
+
+	r := &RuleStore{cache: make(map[ruleKey]Rule)}
+	for _, rule := range rules {
+		r.cache[ruleKey{ruleID: rule.RuleID, clientID: rule.ClientID}] = rule
+		if IsPattern(rule.RuleID) && !slices.Contains(r.patterns, rule.RuleID) {
+			r.patterns = append(r.patterns, rule.RuleID)
+		}
+	}
+	sortPatterns(r.patterns)
+
+	tests := []struct {
+		name             string
+		ruleID, clientID string
+		wantLimit        int // 0 for no rule
+	}{
+		{name: "exact match beats a pattern", ruleID: "api.v1.users", wantLimit: 1},
+		{name: "longer literal beats shorter", ruleID: "api.v1.posts", wantLimit: 2},
+		{name: "only the short pattern matches", ruleID: "api.v2.posts", wantLimit: 3},
+		// "api.*" and "*.ord" both have four literal characters
+		{name: "ties go to the alphabetically first", ruleID: "api.ord", wantLimit: 4},
+		{name: "client override of a pattern", ruleID: "api.v2.posts", clientID: "acme", wantLimit: 5},
+		{name: "client without an override", ruleID: "api.v2.posts", clientID: "other", wantLimit: 3},
+		// The rule ID decides first, the client override only among rules for that ID
+		{name: "client override of the exact rule beats everything", ruleID: "api.v1.users", clientID: "acme", wantLimit: 6},
+		{name: "exact rule beats a client override of a pattern", ruleID: "api.v2.orders", clientID: "acme", wantLimit: 7},
+		{name: "longer pattern beats a client override of a shorter one", ruleID: "api.v1.posts", clientID: "acme", wantLimit: 2},
+		{name: "other clients get the exact rule", ruleID: "api.v1.users", clientID: "other", wantLimit: 1},
+		{name: "nothing matches", ruleID: "web.home"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, ok := r.resolve(tt.ruleID, tt.clientID)
+			if ok != (tt.wantLimit != 0) {
+				t.Fatalf("resolve(%q) found = %v, want %v", tt.ruleID, ok, tt.wantLimit != 0)
+			}
+			if rule.Limit != tt.wantLimit {
+				t.Errorf("resolve(%q) picked %s with limit %d, want limit %d", tt.ruleID, rule.RuleID, rule.Limit, tt.wantLimit)
+			}
+		})
+	}
+}
+
+func TestFindRuleThrottlesMissReloads(t *testing.T) {
+	// No database: a reload would panic, so a miss right after one must be
+	// answered from the cache
+	r := &RuleStore{
+		cache:       map[ruleKey]Rule{{ruleID: "login"}: {RuleID: "login", Limit: 5}},
+		cacheUntil:  time.Now().Add(time.Minute),
+		refreshedAt: time.Now(),
+	}
+
+	if _, ok, err := r.FindRule(context.Background(), "unconfigured", ""); ok || err != nil {
+		t.Fatalf("FindRule(unconfigured) = %v, %v, want a miss", ok, err)
+	}
+	if rule, ok, err := r.FindRule(context.Background(), "login", ""); !ok || err != nil || rule.Limit != 5 {
+		t.Fatalf("FindRule(login) = %+v, %v, %v, want the cached rule", rule, ok, err)
+	}
+}
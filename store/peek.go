@@ -0,0 +1,253 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/redis/go-redis/v9"
+)
+
+// Peek scripts only read. They bring continuous state (refill, drain) up to now
+// in the script itself but never write it back, so a peek changes nothing. Go
+// works out the Result the same way Allow does for a cost of one.
+
+// numberPeekScript serves fixed window counters and GCRA's TAT, 0 when missing
+var numberPeekScript = redis.NewScript(`
+	return tonumber(redis.call('GET', KEYS[1])) or 0
+`)
+
+var slidingWindowPeekScript = redis.NewScript(`
+	local key = KEYS[1]
+	local window_start = tonumber(ARGV[1])
+	local after_start = ARGV[2] -- "(" and window_start as Go formatted it, concatenating the number would round it
+	local limit = tonumber(ARGV[3])
+
+	-- Entries at or before window_start are expired, Allow just hasn't trimmed them yet
+	local count = redis.call('ZCOUNT', key, after_start, '+inf')
+
+	-- One more fits once the oldest (count + 1 - limit) entries have left
+	local freed_at = 0
+	if count >= limit then
+		local needed = redis.call('ZRANGEBYSCORE', key, after_start, '+inf', 'WITHSCORES', 'LIMIT', count - limit, 1)
+		if needed[2] then freed_at = tonumber(needed[2]) end
+	end
+
+	local newest_at = 0
+	local newest = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+	if newest[2] and tonumber(newest[2]) > window_start then newest_at = tonumber(newest[2]) end
+
+	return {count, freed_at, newest_at}
+`)
+
+var slidingWindowCounterPeekScript = redis.NewScript(`
+	local previous = tonumber(redis.call('GET', KEYS[2])) or 0
+	local current = tonumber(redis.call('GET', KEYS[1])) or 0
+	return {previous, current}
+`)
+
+var tokenBucketPeekScript = redis.NewScript(`
+	local tokens_key = KEYS[1]
+	local last_refill_key = KEYS[2]
+	local capacity = tonumber(ARGV[1])
+	local refill_rate = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+
+	-- A missing bucket is a full one
+	local tokens = tonumber(redis.call('GET', tokens_key))
+	if tokens == nil then return tostring(capacity) end
+	local last_refill = tonumber(redis.call('GET', last_refill_key)) or now
+
+	tokens = math.min(capacity, tokens + math.max(0, now - last_refill) / 1000000.0 * refill_rate)
+	return tostring(tokens)
+`)
+
+var leakyBucketPeekScript = redis.NewScript(`
+	local key = KEYS[1]
+	local leak_rate = tonumber(ARGV[1])
+	local now = tonumber(ARGV[2])
+
+	local state = redis.call('HMGET', key, 'level', 'last_leak')
+	local level = tonumber(state[1])
+	if level == nil then return '0' end
+	local last_leak = tonumber(state[2]) or now
+
+	level = math.max(0, level - math.max(0, now - last_leak) / 1000000.0 * leak_rate)
+	return tostring(level)
+`)
+
+var (
+	_ limiter.Peeker = (*AtomicLimiter)(nil)
+	_ limiter.Peeker = (*AtomicSlidingWindowLimiter)(nil)
+	_ limiter.Peeker = (*AtomicSlidingWindowCounterLimiter)(nil)
+	_ limiter.Peeker = (*AtomicTokenBucketLimiter)(nil)
+	_ limiter.Peeker = (*AtomicLeakyBucketLimiter)(nil)
+	_ limiter.Peeker = (*AtomicGCRALimiter)(nil)
+)
+
+// Peek reports the current window. With nothing counted in it the client
+// already has its full allowance, so ResetAt is now.
+func (a *AtomicLimiter) Peek(ctx context.Context, clientID string) (limiter.Result, error) {
+	now := time.Now()
+	count, err := numberPeekScript.Run(ctx, a.client, []string{a.key(clientID, now)}).Int()
+	if err != nil {
+		return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+	}
+
+	_, windowEnd := a.Window(now)
+	res := limiter.Result{Allowed: count+1 <= a.limit, Remaining: max(0, a.limit-count), ResetAt: now}
+	if count > 0 {
+		res.ResetAt = windowEnd
+	}
+	if !res.Allowed {
+		res.RetryAfter = windowEnd.Sub(now)
+	}
+	return res, nil
+}
+
+func (a *AtomicSlidingWindowLimiter) Peek(ctx context.Context, clientID string) (limiter.Result, error) {
+	now := time.Now()
+	windowStart := now.Add(-a.windowSize).UnixMicro()
+	result, err := slidingWindowPeekScript.Run(
+		ctx,
+		a.client,
+		[]string{a.key(clientID)},
+		windowStart,
+		"("+strconv.FormatInt(windowStart, 10),
+		a.limit,
+	).Int64Slice()
+
+	if err != nil {
+		return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+	}
+
+	count := int(result[0])
+	res := limiter.Result{Allowed: count+1 <= a.limit, Remaining: max(0, a.limit-count), ResetAt: now}
+	if newestAt := result[2]; newestAt > 0 {
+		res.ResetAt = time.UnixMicro(newestAt).Add(a.windowSize)
+	}
+	if !res.Allowed {
+		res.RetryAfter = a.windowSize
+		if freedAt := result[1]; freedAt > 0 {
+			res.RetryAfter = time.UnixMicro(freedAt).Add(a.windowSize).Sub(now)
+		}
+	}
+	return res, nil
+}
+
+func (a *AtomicSlidingWindowCounterLimiter) Peek(ctx context.Context, clientID string) (limiter.Result, error) {
+	now := time.Now()
+	currentKey, previousKey := a.keys(clientID, now)
+	elapsed := now.Sub(now.Truncate(a.windowSize))
+	previousWeight := 1 - float64(elapsed)/float64(a.windowSize)
+
+	result, err := slidingWindowCounterPeekScript.Run(ctx, a.client, []string{currentKey, previousKey}).Int64Slice()
+	if err != nil {
+		return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+	}
+
+	previous, current := float64(result[0]), float64(result[1])
+	estimated := previous*previousWeight + current
+
+	res := limiter.Result{
+		Allowed:   estimated+1 <= float64(a.limit),
+		Remaining: max(0, int(math.Floor(float64(a.limit)-estimated))),
+		ResetAt:   now,
+	}
+
+	untilWindowEnd := a.windowSize - elapsed
+	switch {
+	case current > 0:
+		res.ResetAt = now.Add(untilWindowEnd + a.windowSize)
+	case previous > 0:
+		res.ResetAt = now.Add(untilWindowEnd)
+	}
+
+	if !res.Allowed {
+		res.RetryAfter = a.retryAfter(previous, current, 1, elapsed)
+	}
+	return res, nil
+}
+
+func (a *AtomicTokenBucketLimiter) Peek(ctx context.Context, clientID string) (limiter.Result, error) {
+	tokensKey, lastRefillKey := a.keys(clientID)
+	now := time.Now()
+
+	tokensStr, err := tokenBucketPeekScript.Run(
+		ctx,
+		a.client,
+		[]string{tokensKey, lastRefillKey},
+		a.capacity,
+		a.refillRate,
+		now.UnixMicro(),
+	).Text()
+
+	if err != nil {
+		return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+	}
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return limiter.Result{}, fmt.Errorf("invalid token count %q: %w", tokensStr, err)
+	}
+
+	res := limiter.Result{
+		Allowed:   tokens >= 1,
+		Remaining: int(math.Floor(tokens)),
+		ResetAt:   now.Add(durationUntil(float64(a.capacity)-tokens, a.refillRate)),
+	}
+	if !res.Allowed {
+		res.RetryAfter = durationUntil(1-tokens, a.refillRate)
+	}
+	return res, nil
+}
+
+func (a *AtomicLeakyBucketLimiter) Peek(ctx context.Context, clientID string) (limiter.Result, error) {
+	now := time.Now()
+
+	levelStr, err := leakyBucketPeekScript.Run(
+		ctx,
+		a.client,
+		[]string{a.key(clientID)},
+		a.leakRate,
+		now.UnixMicro(),
+	).Text()
+
+	if err != nil {
+		return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+	}
+	level, err := strconv.ParseFloat(levelStr, 64)
+	if err != nil {
+		return limiter.Result{}, fmt.Errorf("invalid bucket level %q: %w", levelStr, err)
+	}
+
+	return limiter.Result{
+		Allowed:    level+1 <= float64(a.capacity),
+		Remaining:  max(0, int(math.Floor(float64(a.capacity)-level))),
+		RetryAfter: durationUntil(level+1-float64(a.capacity), a.leakRate),
+		ResetAt:    now.Add(durationUntil(level, a.leakRate)),
+	}, nil
+}
+
+// Peek works the GCRA maths out in Go, the script only fetches the TAT
+func (a *AtomicGCRALimiter) Peek(ctx context.Context, clientID string) (limiter.Result, error) {
+	now := time.Now().UnixMicro()
+	tat, err := numberPeekScript.Run(ctx, a.client, []string{a.key(clientID)}).Int64()
+	if err != nil {
+		return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+	}
+	tat = max(tat, now)
+
+	interval := a.emissionInterval.Microseconds()
+	tolerance := interval * int64(a.burst)
+	allowAt := tat + interval - tolerance
+
+	return limiter.Result{
+		Allowed:    now >= allowAt,
+		Remaining:  int(max(0, (tolerance-(tat-now))/interval)),
+		RetryAfter: time.Duration(max(0, allowAt-now)) * time.Microsecond,
+		ResetAt:    time.UnixMicro(tat),
+	}, nil
+}
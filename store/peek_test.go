@@ -0,0 +1,193 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSlidingWindowPeekBound(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	windowStart := time.Date(2026, 1, 1, 0, 0, 0, 123456000, time.UTC).UnixMicro()
+	client.ZAdd(ctx, "sliding",
+		redis.Z{Score: float64(windowStart), Member: "expired"},
+		redis.Z{Score: float64(windowStart + 1), Member: "a"},
+		redis.Z{Score: float64(windowStart + 2), Member: "b"},
+	)
+
+	result, err := slidingWindowPeekScript.Run(ctx, client, []string{"sliding"},
+		windowStart, "("+strconv.FormatInt(windowStart, 10), 2).Int64Slice()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The entry right on the bound has left, the one a microsecond later
+	// decides when the next unit fits
+	if want := []int64{2, windowStart + 1, windowStart + 2}; !slices.Equal(result, want) {
+		t.Errorf("peek = %v, want %v", result, want)
+	}
+}
+
+func TestNumberPeek(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	tat := time.Date(2026, 1, 1, 0, 0, 0, 123457000, time.UTC).UnixMicro()
+	mr.Set("counter", "7")
+	mr.Set("tat", strconv.FormatInt(tat, 10))
+
+	tests := []struct {
+		key  string
+		want int64
+	}{
+		{key: "counter", want: 7},
+		{key: "tat", want: tat},
+		{key: "missing", want: 0},
+	}
+
+	for _, tt := range tests {
+		got, err := numberPeekScript.Run(ctx, client, []string{tt.key}).Int64()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("peek %s = %d, want %d", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestSlidingWindowCounterPeek(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	mr.Set("current", "3")
+	mr.Set("previous", "4")
+	mr.Set("only_previous", "5")
+
+	tests := []struct {
+		name              string
+		current, previous string
+		want              []int64
+	}{
+		{name: "both windows", current: "current", previous: "previous", want: []int64{4, 3}},
+		{name: "nothing counted yet this window", current: "missing", previous: "only_previous", want: []int64{5, 0}},
+		{name: "new client", current: "missing", previous: "missing", want: []int64{0, 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := slidingWindowCounterPeekScript.Run(ctx, client, []string{tt.current, tt.previous}).Int64Slice()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("peek = %v, want {previous, current} %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenBucketPeek(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// Holds 5, refills 2 a second, 2 left as of lastRefill
+	lastRefill := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro()
+	tests := []struct {
+		name    string
+		missing bool
+		at      int64 // µs since lastRefill
+		want    string
+	}{
+		{name: "new client has a full bucket", missing: true, want: "5"},
+		{name: "nothing refilled yet", at: 0, want: "2"},
+		{name: "refilled part way", at: 500000, want: "3"},
+		{name: "refill stops at capacity", at: 10000000, want: "5"},
+		{name: "clock behind the last refill", at: -500000, want: "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr.FlushAll()
+			if !tt.missing {
+				mr.Set("tokens", "2")
+				mr.Set("last_refill", strconv.FormatInt(lastRefill, 10))
+			}
+
+			got, err := tokenBucketPeekScript.Run(ctx, client, []string{"tokens", "last_refill"}, 5, 2.0, lastRefill+tt.at).Text()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("peek = %s tokens, want %s", got, tt.want)
+			}
+
+			// Only read, the refill isn't written back
+			if tt.missing {
+				if keys := mr.Keys(); len(keys) != 0 {
+					t.Errorf("peek created %v", keys)
+				}
+			} else if tokens, refill := mustGet(t, mr, "tokens"), mustGet(t, mr, "last_refill"); tokens != "2" || refill != strconv.FormatInt(lastRefill, 10) {
+				t.Errorf("peek changed the bucket to %s tokens as of %s", tokens, refill)
+			}
+		})
+	}
+}
+
+func TestLeakyBucketPeek(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// Drains 2 a second, 3 in the bucket as of lastLeak
+	lastLeak := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro()
+	tests := []struct {
+		name    string
+		missing bool
+		at      int64 // µs since lastLeak
+		want    string
+	}{
+		{name: "new client has an empty bucket", missing: true, want: "0"},
+		{name: "nothing drained yet", at: 0, want: "3"},
+		{name: "drained part way", at: 500000, want: "2"},
+		{name: "drain stops at empty", at: 10000000, want: "0"},
+		{name: "clock behind the last leak", at: -500000, want: "3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr.FlushAll()
+			if !tt.missing {
+				mr.HSet("leaky", "level", "3", "last_leak", strconv.FormatInt(lastLeak, 10))
+			}
+
+			got, err := leakyBucketPeekScript.Run(ctx, client, []string{"leaky"}, 2.0, lastLeak+tt.at).Text()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("peek = level %s, want %s", got, tt.want)
+			}
+
+			// Only read, the drain isn't written back
+			if tt.missing {
+				if keys := mr.Keys(); len(keys) != 0 {
+					t.Errorf("peek created %v", keys)
+				}
+			} else if level, leak := mr.HGet("leaky", "level"), mr.HGet("leaky", "last_leak"); level != "3" || leak != strconv.FormatInt(lastLeak, 10) {
+				t.Errorf("peek changed the bucket to level %s as of %s", level, leak)
+			}
+		})
+	}
+}
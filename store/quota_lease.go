@@ -0,0 +1,92 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// How long after a quota lease expires its unused permits can still be returned.
+// Covers a client returning them right as the lease runs out.
+const quotaLeaseReturnGrace = 10 * time.Second
+
+// takeQuotaLeaseScript reads and deletes a lease record in one step, so its
+// permits can only be returned once
+var takeQuotaLeaseScript = redis.NewScript(`
+	local key = KEYS[1]
+
+	local lease = redis.call('HMGET', key, 'permits', 'leased_at')
+	if not lease[1] then
+		return {0, 0}
+	end
+
+	redis.call('DEL', key)
+	return {tonumber(lease[1]), tonumber(lease[2])}
+`)
+
+// QuotaLease is a batch of permits consumed up front for a client to spend locally
+type QuotaLease struct {
+	ID        string
+	Permits   int
+	LeasedAt  time.Time // when the permits were consumed, refunds go back to that window
+	ExpiresAt time.Time
+}
+
+// QuotaLeaseStore remembers outstanding quota leases, so unused permits are
+// given back at most once and never more than were granted.
+type QuotaLeaseStore struct {
+	client redis.UniversalClient
+}
+
+func NewQuotaLeaseStore(client redis.UniversalClient) *QuotaLeaseStore {
+	return &QuotaLeaseStore{client: client}
+}
+
+func (q *QuotaLeaseStore) key(clientKey, leaseID string) string {
+	return fmt.Sprintf("rate:atomic:quota_lease:%s:%s", hashTag(clientKey), leaseID)
+}
+
+// Grant records a new lease of permits for clientKey
+func (q *QuotaLeaseStore) Grant(ctx context.Context, clientKey string, permits int, leasedAt time.Time, ttl time.Duration) (QuotaLease, error) {
+	leaseID, err := newLeaseID()
+	if err != nil {
+		return QuotaLease{}, fmt.Errorf("lease id: %w", err)
+	}
+
+	lease := QuotaLease{
+		ID:        leaseID,
+		Permits:   permits,
+		LeasedAt:  leasedAt,
+		ExpiresAt: leasedAt.Add(ttl),
+	}
+
+	key := q.key(clientKey, leaseID)
+	pipe := q.client.TxPipeline()
+	pipe.HSet(ctx, key, "permits", permits, "leased_at", leasedAt.UnixMicro())
+	pipe.PExpireAt(ctx, key, lease.ExpiresAt.Add(quotaLeaseReturnGrace))
+	if _, err := pipe.Exec(ctx); err != nil {
+		return QuotaLease{}, fmt.Errorf("redis error: %w", err)
+	}
+
+	return lease, nil
+}
+
+// Take removes a lease and returns it. ok is false if it was never granted,
+// already returned, or expired past the grace period.
+func (q *QuotaLeaseStore) Take(ctx context.Context, clientKey, leaseID string) (QuotaLease, bool, error) {
+	result, err := takeQuotaLeaseScript.Run(ctx, q.client, []string{q.key(clientKey, leaseID)}).Int64Slice()
+	if err != nil {
+		return QuotaLease{}, false, fmt.Errorf("lua script error: %w", err)
+	}
+	if result[0] == 0 {
+		return QuotaLease{}, false, nil
+	}
+
+	return QuotaLease{
+		ID:       leaseID,
+		Permits:  int(result[0]),
+		LeasedAt: time.UnixMicro(result[1]),
+	}, true, nil
+}
@@ -1 +1,38 @@
-package store
\ No newline at end of file
+package store
+
+import (
+	"net/url"
+	"strings"
+)
+
+// hashTag wraps id in a Redis Cluster hash tag so every key built from it
+// hashes to the same slot, which multi-key scripts need. Ids that already
+// carry a tag, like ClientKey's "rule:{client}", keep theirs.
+func hashTag(id string) string {
+	if open := strings.IndexByte(id, '{'); open >= 0 {
+		if end := strings.IndexByte(id[open+1:], '}'); end > 0 {
+			return id
+		}
+	}
+	return "{" + id + "}"
+}
+
+// ClientKey is the key a client's counters for a rule live under. Tagging the
+// client rather than the rule keeps all of one client's rules on one cluster
+// slot, so a multi-rule check can touch them in a single script.
+//
+// Anonymous checks get "_" for a tag: an empty "{}" isn't a hash tag, so the
+// slot would follow the rule instead. Client IDs are query-escaped with "_"
+// escaped too, so no client can share the anonymous counter, or break the tag
+// with braces of its own.
+func ClientKey(ruleID, clientID string) string {
+	if clientID == "" {
+		return tagKey(ruleID, "_")
+	}
+	return tagKey(ruleID, strings.ReplaceAll(url.QueryEscape(clientID), "_", "%5F"))
+}
+
+// tagKey is the key under ruleID for an already escaped hash tag
+func tagKey(ruleID, tag string) string {
+	return ruleID + ":{" + tag + "}"
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/redis/go-redis/v9"
+)
+
+// Refund scripts give units back without ever taking usage below zero, and
+// return how many were actually given back, rounded to whole units for the
+// continuous algorithms. A missing key means nothing is in use, so there is
+// nothing to refund.
+
+// counterRefundScript serves fixed window and sliding window counter buckets
+var counterRefundScript = redis.NewScript(`
+	local key = KEYS[1]
+	local amount = tonumber(ARGV[1])
+
+	local count = tonumber(redis.call('GET', key)) or 0
+	amount = math.min(amount, count)
+	if amount <= 0 then return 0 end
+
+	redis.call('DECRBY', key, amount)
+	return amount
+`)
+
+var slidingWindowRefundScript = redis.NewScript(`
+	local key = KEYS[1]
+	local window_start = tonumber(ARGV[1])
+	local amount = tonumber(ARGV[2])
+
+	-- Only entries still inside the window count as usage
+	redis.call('ZREMRANGEBYSCORE', key, '0', window_start)
+	amount = math.min(amount, redis.call('ZCARD', key))
+	if amount <= 0 then return 0 end
+
+	-- Most recent entries go first
+	redis.call('ZREMRANGEBYRANK', key, -amount, -1)
+	return amount
+`)
+
+var tokenBucketRefundScript = redis.NewScript(`
+	local tokens_key = KEYS[1]
+	local last_refill_key = KEYS[2]
+	local capacity = tonumber(ARGV[1])
+	local refill_rate = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+	local ttl = tonumber(ARGV[4])
+	local amount = tonumber(ARGV[5])
+
+	local tokens = tonumber(redis.call('GET', tokens_key))
+	if tokens == nil then return 0 end
+	local last_refill = tonumber(redis.call('GET', last_refill_key)) or now
+
+	-- Refill up to now first so the cap is applied to the real level
+	tokens = math.min(capacity, tokens + (now - last_refill) / 1000000.0 * refill_rate)
+	local refunded = math.min(capacity, tokens + amount) - tokens
+	if refunded <= 0 then return 0 end
+
+	redis.call('SET', tokens_key, tostring(tokens + refunded), 'EX', ttl)
+	redis.call('SET', last_refill_key, string.format('%d', now), 'EX', ttl)
+	return math.floor(refunded + 0.5)
+`)
+
+var leakyBucketRefundScript = redis.NewScript(`
+	local key = KEYS[1]
+	local leak_rate = tonumber(ARGV[1])
+	local now = tonumber(ARGV[2])
+	local ttl = tonumber(ARGV[3])
+	local amount = tonumber(ARGV[4])
+
+	local state = redis.call('HMGET', key, 'level', 'last_leak')
+	local level = tonumber(state[1])
+	if level == nil then return 0 end
+	local last_leak = tonumber(state[2]) or now
+
+	level = math.max(0, level - math.max(0, now - last_leak) / 1000000.0 * leak_rate)
+	local refunded = math.min(amount, level)
+	if refunded <= 0 then return 0 end
+
+	redis.call('HSET', key, 'level', tostring(level - refunded), 'last_leak', string.format('%d', now))
+	redis.call('EXPIRE', key, ttl)
+	return math.floor(refunded + 0.5)
+`)
+
+var gcraRefundScript = redis.NewScript(`
+	local key = KEYS[1]
+	local emission_interval = tonumber(ARGV[1])
+	local now = tonumber(ARGV[2])
+	local amount = tonumber(ARGV[3])
+
+	-- Each unit in use pushed TAT one emission interval past now
+	local tat = tonumber(redis.call('GET', key))
+	if tat == nil or tat <= now then return 0 end
+
+	local new_tat = math.max(now, tat - emission_interval * amount)
+	if new_tat > now then
+		redis.call('SET', key, new_tat, 'PX', math.ceil((new_tat - now) / 1000))
+	else
+		redis.call('DEL', key)
+	end
+	return math.floor((tat - new_tat) / emission_interval + 0.5)
+`)
+
+var (
+	_ limiter.Refunder = (*AtomicLimiter)(nil)
+	_ limiter.Refunder = (*AtomicSlidingWindowLimiter)(nil)
+	_ limiter.Refunder = (*AtomicSlidingWindowCounterLimiter)(nil)
+	_ limiter.Refunder = (*AtomicTokenBucketLimiter)(nil)
+	_ limiter.Refunder = (*AtomicLeakyBucketLimiter)(nil)
+	_ limiter.Refunder = (*AtomicGCRALimiter)(nil)
+)
+
+// runRefund runs a refund script and reads back the amount refunded
+func runRefund(ctx context.Context, client redis.UniversalClient, script *redis.Script, keys []string, args ...interface{}) (int, error) {
+	refunded, err := script.Run(ctx, client, keys, args...).Int()
+	if err != nil {
+		return 0, fmt.Errorf("lua script error: %w", err)
+	}
+	return refunded, nil
+}
+
+// Refund gives units back to the window they were consumed in. Once that
+// window has ended there is nothing left to refund.
+func (a *AtomicLimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	return runRefund(ctx, a.client, counterRefundScript, []string{a.key(clientID, consumedAt)}, amount)
+}
+
+func (a *AtomicSlidingWindowLimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	windowStart := time.Now().Add(-a.windowSize).UnixMicro()
+	return runRefund(ctx, a.client, slidingWindowRefundScript, []string{a.key(clientID)}, windowStart, amount)
+}
+
+// Refund takes units off the bucket they were counted in, current or previous
+func (a *AtomicSlidingWindowCounterLimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	key, _ := a.keys(clientID, consumedAt)
+	return runRefund(ctx, a.client, counterRefundScript, []string{key}, amount)
+}
+
+func (a *AtomicTokenBucketLimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	tokensKey, lastRefillKey := a.keys(clientID)
+	return runRefund(ctx, a.client, tokenBucketRefundScript, []string{tokensKey, lastRefillKey},
+		a.capacity, a.refillRate, time.Now().UnixMicro(), int(a.ttl.Seconds()), amount)
+}
+
+func (a *AtomicLeakyBucketLimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	return runRefund(ctx, a.client, leakyBucketRefundScript, []string{a.key(clientID)},
+		a.leakRate, time.Now().UnixMicro(), int(a.ttl.Seconds()), amount)
+}
+
+func (a *AtomicGCRALimiter) Refund(ctx context.Context, clientID string, amount int, consumedAt time.Time) (int, error) {
+	return runRefund(ctx, a.client, gcraRefundScript, []string{a.key(clientID)},
+		a.emissionInterval.Microseconds(), time.Now().UnixMicro(), amount)
+}
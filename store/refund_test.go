@@ -0,0 +1,254 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// The refund scripts run directly with a fixed now, like the check scripts in
+// scripts_test.go
+
+var refundNow = time.Date(2026, 1, 1, 0, 0, 30, 0, time.UTC)
+
+func newRefundClient(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	return mr, redis.NewClient(&redis.Options{Addr: mr.Addr()})
+}
+
+func TestCounterRefund(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     string // "" for no key
+		amount    int
+		want      int
+		wantCount string // "" for no key
+	}{
+		{name: "part of the usage", count: "3", amount: 2, want: 2, wantCount: "1"},
+		{name: "more than was used", count: "3", amount: 10, want: 3, wantCount: "0"},
+		{name: "nothing in use", count: "0", amount: 1, want: 0, wantCount: "0"},
+		{name: "missing key", amount: 5, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, client := newRefundClient(t)
+			if tt.count != "" {
+				mr.Set("counter", tt.count)
+			}
+
+			got, err := runRefund(context.Background(), client, counterRefundScript, []string{"counter"}, tt.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("refunded %d, want %d", got, tt.want)
+			}
+			if count, _ := mr.Get("counter"); count != tt.wantCount {
+				t.Errorf("count = %q, want %q", count, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestFixedWindowRefundIntoEndedWindow(t *testing.T) {
+	mr, client := newRefundClient(t)
+	ctx := context.Background()
+	l := NewAtomicFixedWindow(client, 5, time.Minute)
+
+	if _, err := l.Allow(ctx, "login:{acme}", 3); err != nil {
+		t.Fatal(err)
+	}
+	keys := mr.Keys()
+
+	// Units consumed two windows ago: their counter has expired, nothing to give back
+	got, err := l.Refund(ctx, "login:{acme}", 3, time.Now().Add(-2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 0 {
+		t.Errorf("refunded %d into an ended window, want 0", got)
+	}
+	if after := mr.Keys(); len(after) != len(keys) {
+		t.Errorf("keys %v after refunding, want %v", after, keys)
+	}
+
+	got, err = l.Refund(ctx, "login:{acme}", 1, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 1 {
+		t.Errorf("refunded %d into the current window, want 1", got)
+	}
+}
+
+func TestSlidingWindowRefund(t *testing.T) {
+	windowStart := refundNow.Add(-time.Minute).UnixMicro()
+	entries := []redis.Z{
+		{Score: float64(windowStart - 5), Member: "expired"},
+		{Score: float64(windowStart + 1), Member: "oldest"},
+		{Score: float64(windowStart + 2), Member: "middle"},
+		{Score: float64(windowStart + 3), Member: "newest"},
+	}
+
+	tests := []struct {
+		name   string
+		amount int
+		want   int
+		left   []string
+	}{
+		{name: "most recent entries go first", amount: 2, want: 2, left: []string{"oldest"}},
+		// The expired entry isn't in use, it can't be refunded
+		{name: "more than was used", amount: 10, want: 3, left: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newRefundClient(t)
+			ctx := context.Background()
+			client.ZAdd(ctx, "sliding", entries...)
+
+			got, err := runRefund(ctx, client, slidingWindowRefundScript, []string{"sliding"}, windowStart, tt.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("refunded %d, want %d", got, tt.want)
+			}
+
+			left, err := client.ZRange(ctx, "sliding", 0, -1).Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(left, tt.left) {
+				t.Errorf("entries left %v, want %v", left, tt.left)
+			}
+		})
+	}
+
+	t.Run("missing key", func(t *testing.T) {
+		_, client := newRefundClient(t)
+		got, err := runRefund(context.Background(), client, slidingWindowRefundScript, []string{"sliding"}, windowStart, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 0 {
+			t.Errorf("refunded %d, want 0", got)
+		}
+	})
+}
+
+func TestTokenBucketRefund(t *testing.T) {
+	now := refundNow.UnixMicro()
+
+	tests := []struct {
+		name       string
+		tokens     string // "" for no key
+		lastRefill int64
+		amount     int
+		want       int
+		wantTokens string
+	}{
+		{name: "part of the usage", tokens: "1", lastRefill: now, amount: 2, want: 2, wantTokens: "3"},
+		{name: "capped at capacity", tokens: "3", lastRefill: now, amount: 10, want: 2, wantTokens: "5"},
+		// Two seconds at one token a second bring it to 3 before the refund
+		{name: "refill counts towards the cap", tokens: "1", lastRefill: now - 2_000_000, amount: 10, want: 2, wantTokens: "5"},
+		{name: "already full", tokens: "5", lastRefill: now, amount: 1, want: 0, wantTokens: "5"},
+		{name: "missing key", amount: 3, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, client := newRefundClient(t)
+			if tt.tokens != "" {
+				mr.Set("tokens", tt.tokens)
+				client.Set(context.Background(), "last_refill", tt.lastRefill, 0)
+			}
+
+			got, err := runRefund(context.Background(), client, tokenBucketRefundScript,
+				[]string{"tokens", "last_refill"}, 5, 1.0, now, 60, tt.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("refunded %d, want %d", got, tt.want)
+			}
+			if tokens, _ := mr.Get("tokens"); tokens != tt.wantTokens {
+				t.Errorf("tokens = %q, want %q", tokens, tt.wantTokens)
+			}
+		})
+	}
+}
+
+func TestLeakyBucketRefund(t *testing.T) {
+	_, client := newRefundClient(t)
+	ctx := context.Background()
+	now := refundNow.UnixMicro()
+	client.HSet(ctx, "leaky", "level", 2, "last_leak", now)
+
+	got, err := runRefund(ctx, client, leakyBucketRefundScript, []string{"leaky"}, 1.0, now, 60, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != 2 {
+		t.Errorf("refunded %d, want the 2 in the bucket", got)
+	}
+	if level, _ := client.HGet(ctx, "leaky", "level").Float64(); level != 0 {
+		t.Errorf("level = %v, want 0", level)
+	}
+}
+
+func TestGCRARefund(t *testing.T) {
+	now := refundNow.UnixMicro()
+	const interval = 1_000_000 // one unit a second
+
+	tests := []struct {
+		name    string
+		tat     int64 // 0 for no key
+		amount  int
+		want    int
+		wantTAT int64 // 0 for no key
+	}{
+		{name: "rolls TAT back per unit", tat: now + 3*interval, amount: 2, want: 2, wantTAT: now + interval},
+		{name: "more than was used stops at now", tat: now + 3*interval, amount: 10, want: 3},
+		{name: "TAT already passed", tat: now - interval, amount: 1, want: 0, wantTAT: now - interval},
+		{name: "missing key", amount: 1, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr, client := newRefundClient(t)
+			ctx := context.Background()
+			if tt.tat != 0 {
+				client.Set(ctx, "gcra", tt.tat, 0)
+			}
+
+			got, err := runRefund(ctx, client, gcraRefundScript, []string{"gcra"}, interval, now, tt.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("refunded %d, want %d", got, tt.want)
+			}
+
+			if tt.wantTAT == 0 {
+				if mr.Exists("gcra") {
+					t.Errorf("TAT key still there, want it gone")
+				}
+				return
+			}
+			tat, err := client.Get(ctx, "gcra").Int64()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tat != tt.wantTAT {
+				t.Errorf("TAT = %d, want %d", tat, tt.wantTAT)
+			}
+		})
+	}
+}
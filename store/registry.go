@@ -0,0 +1,78 @@
+package store
+
+import (
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRedisRegistry registers every algorithm backed by the Lua scripts in this
+// package. Adding an algorithm means adding it here, CheckLimit and the admin
+// API pick it up from the registry.
+func NewRedisRegistry(client redis.UniversalClient) *limiter.Registry {
+	reg := limiter.NewRegistry()
+
+	reg.Register(limiter.Algorithm{
+		Name:        "fixed_window",
+		Description: "Counter that resets every window_secs, or on calendar boundaries when period is set",
+		New: func(cfg limiter.Config) (limiter.Limiter, error) {
+			if cfg.Period != "" {
+				return NewAtomicCalendarWindow(client, cfg.Limit, cfg.Period, cfg.Location), nil
+			}
+			return NewAtomicFixedWindow(client, cfg.Limit, cfg.Window), nil
+		},
+	})
+	reg.Register(limiter.Algorithm{
+		Name:        "sliding_window",
+		Description: "Exact log of request timestamps over the last window_secs",
+		New: func(cfg limiter.Config) (limiter.Limiter, error) {
+			return NewAtomicSlidingWindow(client, cfg.Limit, cfg.Window), nil
+		},
+	})
+	reg.Register(limiter.Algorithm{
+		Name:        "sliding_window_counter",
+		Description: "Weighted blend of the current and previous fixed windows, two counters per client",
+		New: func(cfg limiter.Config) (limiter.Limiter, error) {
+			return NewAtomicSlidingWindowCounter(client, cfg.Limit, cfg.Window), nil
+		},
+		LocalFallback: "fixed_window",
+	})
+	reg.Register(limiter.Algorithm{
+		Name:        "token_bucket",
+		Description: "Bucket of capacity tokens refilled at refill_rate per second, allows bursts",
+		New: func(cfg limiter.Config) (limiter.Limiter, error) {
+			return NewAtomicTokenBucket(client, cfg.Capacity, cfg.RefillRate), nil
+		},
+		Allowance: limiter.CapacityAllowance,
+	})
+	reg.Register(limiter.Algorithm{
+		Name:        "leaky_bucket",
+		Description: "Admits requests at the drain rate refill_rate per second, with a burst of capacity (default 1)",
+		New: func(cfg limiter.Config) (limiter.Limiter, error) {
+			return NewAtomicLeakyBucket(client, cfg.Capacity, cfg.RefillRate), nil
+		},
+		// Smooths traffic to the drain rate, any room in the bucket is burst on
+		// top. None unless the rule asks for it.
+		DefaultCapacity: 1,
+		Allowance:       limiter.CapacityAllowance,
+		// An empty bucket takes any cost and drains it afterwards
+		AnyCost:       true,
+		LocalFallback: "fixed_window",
+	})
+	reg.Register(limiter.Algorithm{
+		Name:        "gcra",
+		Description: "Generic cell rate algorithm, steady refill_rate with a burst of capacity",
+		New: func(cfg limiter.Config) (limiter.Limiter, error) {
+			return NewAtomicGCRA(client, cfg.Capacity, cfg.RefillRate), nil
+		},
+		Allowance:     limiter.CapacityAllowance,
+		LocalFallback: "fixed_window",
+	})
+
+	// Checked through AcquireConcurrency/ReleaseConcurrency, not CheckLimit
+	reg.Register(limiter.Algorithm{
+		Name:        "in_flight",
+		Description: "Caps concurrent leases per client, acquired and released around the work",
+	})
+
+	return reg
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cynkin/rlaas/limiter"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	// ErrNoCounters is returned for algorithms without counters to reset, like in_flight
+	ErrNoCounters = errors.New("algorithm keeps no counters to reset")
+	// ErrResetAllUnsupported is returned when resetting every client without Redis
+	ErrResetAllUnsupported = errors.New("resetting every client needs the redis backend")
+)
+
+var (
+	_ limiter.Resetter = (*AtomicLimiter)(nil)
+	_ limiter.Resetter = (*AtomicSlidingWindowLimiter)(nil)
+	_ limiter.Resetter = (*AtomicSlidingWindowCounterLimiter)(nil)
+	_ limiter.Resetter = (*AtomicTokenBucketLimiter)(nil)
+	_ limiter.Resetter = (*AtomicLeakyBucketLimiter)(nil)
+	_ limiter.Resetter = (*AtomicGCRALimiter)(nil)
+)
+
+// Reset clears the current window, earlier ones no longer count anyway
+func (a *AtomicLimiter) Reset(ctx context.Context, clientID string) error {
+	return a.client.Unlink(ctx, a.key(clientID, time.Now())).Err()
+}
+
+func (a *AtomicSlidingWindowLimiter) Reset(ctx context.Context, clientID string) error {
+	return a.client.Unlink(ctx, a.key(clientID)).Err()
+}
+
+// Reset clears both buckets the estimate is made from
+func (a *AtomicSlidingWindowCounterLimiter) Reset(ctx context.Context, clientID string) error {
+	currentKey, previousKey := a.keys(clientID, time.Now())
+	return a.client.Unlink(ctx, currentKey, previousKey).Err()
+}
+
+func (a *AtomicTokenBucketLimiter) Reset(ctx context.Context, clientID string) error {
+	tokensKey, lastRefillKey := a.keys(clientID)
+	return a.client.Unlink(ctx, tokensKey, lastRefillKey).Err()
+}
+
+func (a *AtomicLeakyBucketLimiter) Reset(ctx context.Context, clientID string) error {
+	return a.client.Unlink(ctx, a.key(clientID)).Err()
+}
+
+func (a *AtomicGCRALimiter) Reset(ctx context.Context, clientID string) error {
+	return a.client.Unlink(ctx, a.key(clientID)).Err()
+}
+
+// CounterResetter clears rule counters by hand, for support staff unblocking a
+// client without guessing key layouts in redis-cli
+type CounterResetter struct {
+	client   redis.UniversalClient // nil with the memory backend
+	registry *limiter.Registry
+}
+
+func NewCounterResetter(client redis.UniversalClient, registry *limiter.Registry) *CounterResetter {
+	return &CounterResetter{client: client, registry: registry}
+}
+
+// Reset clears the counters under one key of the rule, the key CheckLimit
+// counts the client under (see Rule.CounterKey)
+func (r *CounterResetter) Reset(ctx context.Context, rule Rule, counterKey string) error {
+	cfg, err := rule.Config()
+	if err != nil {
+		return fmt.Errorf("rule %q: %w", rule.RuleID, err)
+	}
+
+	l, err := r.registry.New(rule.Algorithm, cfg)
+	if errors.Is(err, limiter.ErrNotRateLimiter) {
+		return ErrNoCounters
+	}
+	if err != nil {
+		return fmt.Errorf("rule %q: %w", rule.RuleID, err)
+	}
+
+	resetter, ok := l.(limiter.Resetter)
+	if !ok {
+		return ErrNoCounters
+	}
+	return resetter.Reset(ctx, counterKey)
+}
+
+// Key prefixes of the atomic limiters, see the key methods in scripts.go
+var counterPrefixes = []string{"fixed", "sliding", "swc", "bucket", "leaky", "gcra"}
+
+// ResetAll clears every client's counters under counterID (a rule ID, or a
+// pattern covering several), whichever algorithm wrote them, and returns how
+// many keys went. It scans the keyspace, so it is for occasional manual use.
+func (r *CounterResetter) ResetAll(ctx context.Context, counterID string) (int, error) {
+	if r.client == nil {
+		return 0, ErrResetAllUnsupported
+	}
+
+	// Keys look like rate:atomic:fixed:<rule>:{client}:<window>. SCAN narrows it
+	// down, counterKeyRule checks the algorithm and rule exactly.
+	match := "rate:atomic:*:" + globEscape(counterID) + ":{*"
+
+	var cleared atomic.Int64
+	scan := func(ctx context.Context, node *redis.Client) error {
+		iter := node.Scan(ctx, 0, match, 500).Iterator()
+		pipe := node.Pipeline()
+		for iter.Next(ctx) {
+			if ruleID, ok := counterKeyRule(iter.Val()); ok && matchPattern(counterID, ruleID) {
+				pipe.Unlink(ctx, iter.Val())
+			}
+		}
+		if err := iter.Err(); err != nil {
+			return err
+		}
+
+		cmds, err := pipe.Exec(ctx)
+		if err != nil {
+			return err
+		}
+		for _, cmd := range cmds {
+			cleared.Add(cmd.(*redis.IntCmd).Val())
+		}
+		return nil
+	}
+
+	var err error
+	switch c := r.client.(type) {
+	case *redis.ClusterClient:
+		err = c.ForEachMaster(ctx, scan)
+	case *redis.Client:
+		err = scan(ctx, c)
+	default:
+		err = fmt.Errorf("unsupported redis client %T", r.client)
+	}
+	if err != nil {
+		return int(cleared.Load()), fmt.Errorf("reset error: %w", err)
+	}
+	return int(cleared.Load()), nil
+}
+
+// counterKeyRule pulls the rule ID out of an atomic limiter key
+func counterKeyRule(key string) (string, bool) {
+	rest, ok := strings.CutPrefix(key, "rate:atomic:")
+	if !ok {
+		return "", false
+	}
+	prefix, rest, ok := strings.Cut(rest, ":")
+	if !ok || !slices.Contains(counterPrefixes, prefix) {
+		return "", false
+	}
+	i := strings.Index(rest, ":{")
+	if i < 0 {
+		return "", false
+	}
+	return rest[:i], true
+}
+
+// globEscape escapes SCAN MATCH syntax in a rule ID, leaving "*" so patterns
+// still cover the rule IDs they match
+func globEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `?`, `\?`, `[`, `\[`, `]`, `\]`).Replace(s)
+}
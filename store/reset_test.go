@@ -0,0 +1,123 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestResetAll(t *testing.T) {
+	// Counters written by the real limiters, so the keys have their real layout
+	counters := []struct {
+		rule     Rule
+		ruleID   string // requested rule ID, for pattern rules
+		clientID string
+		ip       string
+	}{
+		{rule: Rule{RuleID: "login", Algorithm: "fixed_window"}, clientID: "acme"},
+		{rule: Rule{RuleID: "login", Algorithm: "token_bucket"}, clientID: "beta"},
+		{rule: Rule{RuleID: "login", Algorithm: "sliding_window", KeyBy: []string{"ip"}}, ip: "10.0.0.1"},
+		{rule: Rule{RuleID: "loginx", Algorithm: "fixed_window"}, clientID: "acme"},
+		{rule: Rule{RuleID: "x:login", Algorithm: "gcra"}, clientID: "acme"},
+		{rule: Rule{RuleID: "api.*", Algorithm: "fixed_window"}, ruleID: "api.users", clientID: "acme"},
+		{rule: Rule{RuleID: "api.*", Algorithm: "leaky_bucket"}, ruleID: "api.orders", clientID: "beta"},
+		{rule: Rule{RuleID: "web.api.users", Algorithm: "fixed_window"}, clientID: "acme"},
+		{rule: Rule{RuleID: "abc", Algorithm: "fixed_window"}, clientID: "acme"},
+		{rule: Rule{RuleID: "a?c", Algorithm: "fixed_window"}, clientID: "acme"},
+		{rule: Rule{RuleID: "a[b]c", Algorithm: "sliding_window_counter"}, clientID: "acme"},
+		{rule: Rule{RuleID: `a\c`, Algorithm: "fixed_window"}, clientID: "acme"},
+	}
+
+	tests := []struct {
+		name      string
+		counterID string
+		want      []string // rule IDs whose counters go
+		wantKeys  int
+	}{
+		// The token bucket keeps two keys
+		{name: "rule and its key_by counters", counterID: "login", want: []string{"login"}, wantKeys: 4},
+		{name: "longer rule ID sharing the prefix", counterID: "loginx", want: []string{"loginx"}, wantKeys: 1},
+		{name: "rule ID with a colon", counterID: "x:login", want: []string{"x:login"}, wantKeys: 1},
+		{name: "pattern counter IDs", counterID: "api.*", want: []string{"api.orders", "api.users"}, wantKeys: 2},
+		{name: "question mark is literal", counterID: "a?c", want: []string{"a?c"}, wantKeys: 1},
+		{name: "brackets are literal", counterID: "a[b]c", want: []string{"a[b]c"}, wantKeys: 1},
+		{name: "backslash is literal", counterID: `a\c`, want: []string{`a\c`}, wantKeys: 1},
+		{name: "nothing matches", counterID: "signup", wantKeys: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			registry := NewRedisRegistry(client)
+			ctx := context.Background()
+
+			all := map[string]bool{}
+			for _, c := range counters {
+				c.rule.Limit, c.rule.WindowSecs = 10, 60
+				cfg, err := c.rule.Config()
+				if err != nil {
+					t.Fatal(err)
+				}
+				l, err := registry.New(c.rule.Algorithm, cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				d := Descriptors{"client_id": c.clientID, "ip": c.ip}
+				if _, err := l.Allow(ctx, c.rule.CounterKey(c.ruleID, d), 1); err != nil {
+					t.Fatal(err)
+				}
+				all[c.rule.CounterID(c.ruleID)] = true
+			}
+
+			cleared, err := NewCounterResetter(client, registry).ResetAll(ctx, tt.counterID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cleared != tt.wantKeys {
+				t.Errorf("cleared %d keys, want %d", cleared, tt.wantKeys)
+			}
+
+			left := map[string]bool{}
+			for _, key := range mr.Keys() {
+				ruleID, ok := counterKeyRule(key)
+				if !ok {
+					t.Fatalf("counterKeyRule(%q) didn't parse", key)
+				}
+				left[ruleID] = true
+			}
+			for ruleID := range all {
+				if gone := !left[ruleID]; gone != slices.Contains(tt.want, ruleID) {
+					t.Errorf("counters of %q gone = %v, want %v", ruleID, gone, !gone)
+				}
+			}
+		})
+	}
+}
+
+func TestCounterKeyRule(t *testing.T) {
+	tests := []struct {
+		key    string
+		ruleID string
+		ok     bool
+	}{
+		{key: "rate:atomic:fixed:login:{acme}:1767225600", ruleID: "login", ok: true},
+		{key: "rate:atomic:bucket:x:login:{acme}:tokens", ruleID: "x:login", ok: true},
+		{key: "rate:atomic:sliding:login:{ip=10.0.0.1}", ruleID: "login", ok: true},
+		{key: "rate:atomic:gcra:a?[b]\\c:{_}", ruleID: "a?[b]\\c", ok: true},
+		// Not a rate counter: leases and in-flight sets are left alone
+		{key: "rate:atomic:inflight:{acme}", ok: false},
+		{key: "rate:atomic:quota_lease:login:{acme}:lease", ok: false},
+		{key: "other:atomic:fixed:login:{acme}:1", ok: false},
+	}
+
+	for _, tt := range tests {
+		ruleID, ok := counterKeyRule(tt.key)
+		if ruleID != tt.ruleID || ok != tt.ok {
+			t.Errorf("counterKeyRule(%q) = %q, %v, want %q, %v", tt.key, ruleID, ok, tt.ruleID, tt.ok)
+		}
+	}
+}
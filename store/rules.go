@@ -3,75 +3,193 @@ package store
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
+	"github.com/cynkin/rlaas/limiter"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type Rule struct {
-	ID         string
-	RuleID     string
-	ClientID   string // empty means applies to all clients
-	Algorithm  string
-	Limit      int
-	WindowSecs int
-	Enabled    bool
+	ID          string
+	RuleID      string
+	ClientID    string // empty means applies to all clients
+	Algorithm   string
+	Limit       int
+	WindowSecs  int
+	Capacity    int               // token bucket size, or burst (leaky bucket, gcra); 0 means the algorithm's default
+	RefillRate  float64           // refill, drain or emission rate per second, 0 means Limit / WindowSecs
+	Period      string            // fixed_window: "day", "week" or "month" to reset on calendar boundaries instead of WindowSecs
+	Timezone    string            // IANA timezone the calendar period follows, empty means UTC
+	FailureMode string            // what to do when the backend errors, empty means the server default
+	Match       map[string]string // descriptors a check must carry for the rule to apply, see Matches
+	KeyBy       []string          // descriptors counters are kept per, empty means per client
+	Enabled     bool
 }
 
+// Config translates the rule into the settings its algorithm's limiter is built from
+func (r Rule) Config() (limiter.Config, error) {
+	loc, err := LoadTimezone(r.Timezone)
+	if err != nil {
+		return limiter.Config{}, err
+	}
+
+	// Refill, drain or emission rate derived from limit and window when not set
+	refillRate := r.RefillRate
+	if refillRate <= 0 && r.WindowSecs > 0 {
+		refillRate = float64(r.Limit) / float64(r.WindowSecs)
+	}
+	return limiter.Config{
+		Limit:      r.Limit,
+		Window:     time.Duration(r.WindowSecs) * time.Second,
+		Capacity:   r.Capacity,
+		RefillRate: refillRate,
+		Period:     r.Period,
+		Location:   loc,
+	}, nil
+}
+
+// How often a rule ID missing from a fresh cache may reload it. Rules created
+// through another replica's admin API only invalidate that replica's cache, so
+// misses still reload, just not on every check: Envoy sends descriptors nobody
+// configured all the time.
+const missRefreshInterval = time.Second
+
 type RuleStore struct {
-	db         *pgxpool.Pool
-	cache      map[string]Rule
-	cacheMu    sync.RWMutex
-	cacheUntil time.Time
-	cacheTTL   time.Duration
+	db          *pgxpool.Pool
+	cache       map[ruleKey]Rule
+	patterns    []string // rule IDs containing "*", in match precedence order
+	cacheMu     sync.RWMutex
+	cacheUntil  time.Time
+	cacheTTL    time.Duration
+	refreshedAt time.Time // last reload, misses reload at most every missRefreshInterval
+}
+
+// ruleKey identifies a cached rule. clientID is empty for the generic rule.
+type ruleKey struct {
+	ruleID   string
+	clientID string
 }
 
 func NewRuleStore(db *pgxpool.Pool) *RuleStore {
 	return &RuleStore{
 		db:       db,
-		cache:    make(map[string]Rule), // start with empty cache map
+		cache:    make(map[ruleKey]Rule), // start with empty cache map
 		cacheTTL: 30 * time.Second, // rules refresh every 30 seconds
 	}
 }
 
-func (r *RuleStore) GetRule(ctx context.Context, ruleID string) (Rule, error) {
-	// Serve from cache if still fresh
+// NewStaticRuleStore serves a fixed set of rules without a database, for tests
+// and tools. There is nothing to reload, so misses are final.
+func NewStaticRuleStore(rules ...Rule) *RuleStore {
+	r := NewRuleStore(nil)
+	for _, rule := range rules {
+		r.cache[ruleKey{ruleID: rule.RuleID, clientID: rule.ClientID}] = rule
+		if IsPattern(rule.RuleID) && !slices.Contains(r.patterns, rule.RuleID) {
+			r.patterns = append(r.patterns, rule.RuleID)
+		}
+	}
+	sortPatterns(r.patterns)
+	r.refreshedAt = time.Now()
+	r.cacheUntil = r.refreshedAt.Add(r.cacheTTL)
+	return r
+}
+
+// GetRule returns the rule to apply to clientID. An exact rule ID wins over
+// patterns, and the longest matching pattern over shorter ones. Within the
+// chosen ID, a rule scoped to the client takes precedence over the generic one.
+// Rule IDs nothing matches get the "default" rule.
+func (r *RuleStore) GetRule(ctx context.Context, ruleID, clientID string) (Rule, error) {
+	rule, ok, err := r.FindRule(ctx, ruleID, clientID)
+	if err != nil || ok {
+		return rule, err
+	}
+
+	// Fall back to default rule
+	r.cacheMu.RLock()
+	defer r.cacheMu.RUnlock()
+	rule, ok = r.lookup("default", clientID)
+	if !ok {
+		return Rule{}, fmt.Errorf("no rule found for: %s", ruleID)
+	}
+	return rule, nil
+}
+
+// FindRule is GetRule without the fallback: ok is false when neither the rule
+// ID nor any pattern matches. For callers that must not act on "default" by
+// accident, like resets.
+func (r *RuleStore) FindRule(ctx context.Context, ruleID, clientID string) (Rule, bool, error) {
+	// Serve from cache if still fresh. A miss reloads in case the rule was
+	// just created, unless the cache was reloaded a moment ago.
 	r.cacheMu.RLock()
 	if time.Now().Before(r.cacheUntil) {
-		rule, ok := r.cache[ruleID]
+		rule, ok := r.resolve(ruleID, clientID)
+		recent := time.Since(r.refreshedAt) < missRefreshInterval
 		r.cacheMu.RUnlock()
-		if ok {
-			return rule, nil
+		if ok || recent {
+			return rule, ok, nil
 		}
 		// Rule not in cache — fall through to DB
 	} else {
 		r.cacheMu.RUnlock()
 	}
 
-	// Cache is stale — reload all rules from DB
+	// Cache is stale or missed the rule — reload all rules from DB
 	if err := r.refreshCache(ctx); err != nil {
-		return Rule{}, fmt.Errorf("failed to refresh rule cache: %w", err)
+		return Rule{}, false, fmt.Errorf("failed to refresh rule cache: %w", err)
 	}
 
 	r.cacheMu.RLock()
 	defer r.cacheMu.RUnlock()
 
-	rule, ok := r.cache[ruleID]
-	if !ok {
-		// Fall back to default rule
-		rule, ok = r.cache["default"]
-		if !ok {
-			return Rule{}, fmt.Errorf("no rule found for: %s", ruleID)
+	rule, ok := r.resolve(ruleID, clientID)
+	return rule, ok, nil
+}
+
+// resolve tries the exact rule ID, then every pattern matching it. Must be
+// called with cacheMu held.
+func (r *RuleStore) resolve(ruleID, clientID string) (Rule, bool) {
+	if rule, ok := r.lookup(ruleID, clientID); ok {
+		return rule, true
+	}
+	for _, pattern := range r.patterns {
+		if !matchPattern(pattern, ruleID) {
+			continue
+		}
+		if rule, ok := r.lookup(pattern, clientID); ok {
+			return rule, true
 		}
 	}
+	return Rule{}, false
+}
 
-	return rule, nil
+// lookup finds the client's override or the generic rule. Must be called with cacheMu held.
+func (r *RuleStore) lookup(ruleID, clientID string) (Rule, bool) {
+	if clientID != "" {
+		if rule, ok := r.cache[ruleKey{ruleID: ruleID, clientID: clientID}]; ok {
+			return rule, true
+		}
+	}
+	rule, ok := r.cache[ruleKey{ruleID: ruleID}]
+	return rule, ok
 }
 
 func (r *RuleStore) refreshCache(ctx context.Context) error {
+	// Static rules, keep serving them
+	if r.db == nil {
+		r.cacheMu.Lock()
+		r.refreshedAt = time.Now()
+		r.cacheUntil = r.refreshedAt.Add(r.cacheTTL)
+		r.cacheMu.Unlock()
+		return nil
+	}
+
 	rows, err := r.db.Query(ctx, `
-		SELECT rule_id, COALESCE(client_id, ''), algorithm, "limit", window_secs, enabled
+		SELECT rule_id, COALESCE(client_id, ''), algorithm, "limit", window_secs,
+			COALESCE(capacity, 0), COALESCE(refill_rate, 0),
+			COALESCE(period, ''), COALESCE(timezone, ''), COALESCE(failure_mode, ''),
+			COALESCE(match_descriptors, '{}'), COALESCE(key_by, '{}'), enabled
 		FROM rules
 		WHERE enabled = true
 	`)
@@ -80,7 +198,8 @@ func (r *RuleStore) refreshCache(ctx context.Context) error {
 	}
 	defer rows.Close()
 
-	newCache := make(map[string]Rule)
+	newCache := make(map[ruleKey]Rule)
+	var patterns []string
 	for rows.Next() {
 		var rule Rule
 		err := rows.Scan(
@@ -89,17 +208,34 @@ func (r *RuleStore) refreshCache(ctx context.Context) error {
 			&rule.Algorithm,
 			&rule.Limit,
 			&rule.WindowSecs,
+			&rule.Capacity,
+			&rule.RefillRate,
+			&rule.Period,
+			&rule.Timezone,
+			&rule.FailureMode,
+			&rule.Match,
+			&rule.KeyBy,
 			&rule.Enabled,
 		)
 		if err != nil {
 			return fmt.Errorf("scan error: %w", err)
 		}
-		newCache[rule.RuleID] = rule
+		key := ruleKey{ruleID: rule.RuleID, clientID: rule.ClientID}
+		newCache[key] = rule
+
+		// Overrides share their pattern with the generic rule, list it once
+		if IsPattern(rule.RuleID) && !slices.Contains(patterns, rule.RuleID) {
+			patterns = append(patterns, rule.RuleID)
+		}
 	}
 
+	sortPatterns(patterns)
+
 	r.cacheMu.Lock()
 	r.cache = newCache
-	r.cacheUntil = time.Now().Add(r.cacheTTL)
+	r.patterns = patterns
+	r.refreshedAt = time.Now()
+	r.cacheUntil = r.refreshedAt.Add(r.cacheTTL)
 	r.cacheMu.Unlock()
 
 	fmt.Printf("Rule cache refreshed — %d rules loaded\n", len(newCache))
@@ -114,7 +250,7 @@ func (r *RuleStore) SeedDefaultRules(ctx context.Context) error {
 			('login',   'fixed_window',    5, 60),
 			('search',  'sliding_window', 30, 10),
 			('upload',  'fixed_window',    3, 60)
-		ON CONFLICT (rule_id) DO NOTHING
+		ON CONFLICT (rule_id, COALESCE(client_id, '')) DO NOTHING
 	`)
 	return err
 }
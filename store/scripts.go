@@ -3,25 +3,35 @@ package store
 import (
 	"context"
 	"fmt"
+	"math"
+	"strconv"
 	"time"
 
+	"github.com/cynkin/rlaas/limiter"
 	"github.com/redis/go-redis/v9"
 )
 var fixedWindowScript = redis.NewScript(`
 	local key = KEYS[1]
 	local limit = tonumber(ARGV[1])
 	local window = tonumber(ARGV[2])
+	local cost = tonumber(ARGV[3])
+
+	-- Refuse without consuming if this request doesn't fit
+	local count = tonumber(redis.call('GET', key)) or 0
+	if count + cost > limit then
+		return {0, count}
+	end
 
 	-- Atomically increment
-	local count = redis.call('INCR', key)
+	count = redis.call('INCRBY', key, cost)
 
 	-- Set expiry only on first request in this window
-	if count == 1 then
+	if count == cost then
 		redis.call('EXPIRE', key, window)
 	end
 
-	-- Return count so Go can decide allowed/blocked
-	return count
+	-- Return count so Go can work out what's left
+	return {1, count}
 `)
 
 var slidingWindowScript = redis.NewScript(`
@@ -30,6 +40,7 @@ var slidingWindowScript = redis.NewScript(`
 	local window_start = tonumber(ARGV[2])
 	local limit = tonumber(ARGV[3])
 	local ttl = tonumber(ARGV[4])
+	local cost = tonumber(ARGV[5])
 
 	-- Remove entries outside the window
 	redis.call('ZREMRANGEBYSCORE', key, '0', window_start)
@@ -37,14 +48,59 @@ var slidingWindowScript = redis.NewScript(`
 	-- Count entries in current window
 	local count = redis.call('ZCARD', key)
 
-	-- Only add if under limit
-	if count < limit then
-		redis.call('ZADD', key, now, now)
+	-- Only add if the whole cost fits, one entry per unit
+	if count + cost <= limit then
+		-- Members are the timestamp as Go sent it (concatenating the number
+		-- would round it to 100us) and a sequence within that microsecond, so
+		-- checks landing on the same one don't overwrite each other's entries.
+		-- Zero padded so the highest sequence is also the last member a refund
+		-- drops.
+		local seq = redis.call('ZCOUNT', key, now, now)
+		for i = 1, cost do
+			redis.call('ZADD', key, now, string.format('%s:%06d', ARGV[1], seq + i))
+		end
 		redis.call('EXPIRE', key, ttl)
-		return count + 1
+		return {1, count + cost, 0, now}
+	end
+
+	-- Blocked: the request fits once the oldest (count + cost - limit) entries
+	-- have left the window, and the window is empty once the newest has
+	local freed_at = 0
+	local needed = redis.call('ZRANGE', key, count + cost - limit - 1, count + cost - limit - 1, 'WITHSCORES')
+	if needed[2] then freed_at = tonumber(needed[2]) end
+
+	local newest_at = 0
+	local newest = redis.call('ZRANGE', key, -1, -1, 'WITHSCORES')
+	if newest[2] then newest_at = tonumber(newest[2]) end
+
+	return {0, count, freed_at, newest_at}
+`)
+
+var slidingWindowCounterScript = redis.NewScript(`
+	local current_key = KEYS[1]
+	local previous_key = KEYS[2]
+	local limit = tonumber(ARGV[1])
+	local window = tonumber(ARGV[2])
+	local previous_weight = tonumber(ARGV[3]) -- share of the previous window still inside the sliding window
+	local cost = tonumber(ARGV[4])
+
+	local previous = tonumber(redis.call('GET', previous_key)) or 0
+	local current = tonumber(redis.call('GET', current_key)) or 0
+
+	-- Approximate the sliding count from the two fixed buckets
+	local estimated = previous * previous_weight + current
+	if estimated + cost > limit then
+		return {0, previous, current}
 	end
 
-	return count + 1  -- return over-limit count so caller knows to block
+	-- Keep the bucket for two windows so it can serve as "previous" next time
+	current = redis.call('INCRBY', current_key, cost)
+	if current == cost then
+		redis.call('EXPIRE', current_key, window * 2)
+	end
+
+	-- Go works out the estimate, retry and reset from the raw counts
+	return {1, previous, current}
 `)
 
 var tokenBucketScript = redis.NewScript(`
@@ -54,6 +110,7 @@ var tokenBucketScript = redis.NewScript(`
 	local refill_rate = tonumber(ARGV[2])
 	local now = tonumber(ARGV[3])
 	local ttl = tonumber(ARGV[4])
+	local cost = tonumber(ARGV[5])
 
 	-- Get current state
 	local tokens = tonumber(redis.call('GET', tokens_key))
@@ -70,24 +127,105 @@ var tokenBucketScript = redis.NewScript(`
 
 	-- Check and consume
 	local allowed = 0
-	if tokens >= 1.0 then
-		tokens = tokens - 1.0
+	if tokens >= cost then
+		tokens = tokens - cost
 		allowed = 1
 	end
 
 	-- Save state back atomically
 	redis.call('SET', tokens_key, tostring(tokens), 'EX', ttl)
-	redis.call('SET', last_refill_key, tostring(now), 'EX', ttl)
+	redis.call('SET', last_refill_key, string.format('%d', now), 'EX', ttl)
 
 	return {allowed, tostring(tokens)}
 `)
 
+var leakyBucketScript = redis.NewScript(`
+	local key = KEYS[1]
+	local capacity = tonumber(ARGV[1])
+	local leak_rate = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+	local ttl = tonumber(ARGV[4])
+	local cost = tonumber(ARGV[5])
+
+	-- Get current state
+	local state = redis.call('HMGET', key, 'level', 'last_leak')
+	local level = tonumber(state[1])
+	local last_leak = tonumber(state[2])
+
+	-- Default for new clients: empty bucket
+	if level == nil then level = 0 end
+	if last_leak == nil then last_leak = now end
+
+	-- Drain at a constant rate since the last request
+	local elapsed = math.max(0, now - last_leak) / 1000000.0
+	level = math.max(0, level - elapsed * leak_rate)
+
+	-- Admit if there is room, otherwise report how long until there is. An
+	-- empty bucket takes a request costing more than capacity, or it never would.
+	local room = math.max(capacity, cost)
+	local allowed = 0
+	local wait_us = 0
+	if level + cost <= room then
+		level = level + cost
+		allowed = 1
+	else
+		wait_us = math.ceil((level + cost - room) / leak_rate * 1000000)
+	end
+
+	-- Save state back atomically
+	-- A bucket holding more than capacity takes longer than ttl to drain
+	if leak_rate > 0 then ttl = math.max(ttl, math.ceil(level / leak_rate) + 1) end
+	redis.call('HSET', key, 'level', tostring(level), 'last_leak', string.format('%d', now))
+	redis.call('EXPIRE', key, ttl)
+
+	return {allowed, tostring(level), wait_us}
+`)
+
+var gcraScript = redis.NewScript(`
+	local key = KEYS[1]
+	local emission_interval = tonumber(ARGV[1]) -- microseconds between requests at the steady rate
+	local burst = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+	local cost = tonumber(ARGV[4])
+
+	-- Theoretical arrival time: when the client is "caught up" again
+	local tat = tonumber(redis.call('GET', key))
+	if tat == nil or tat < now then tat = now end
+
+	local tolerance = emission_interval * burst
+	local new_tat = tat + emission_interval * cost
+	local allow_at = new_tat - tolerance
+
+	-- Too early: report how long until this request would conform
+	if now < allow_at then
+		local remaining = math.floor((tolerance - (tat - now)) / emission_interval)
+		return {0, math.max(0, remaining), allow_at - now, tat - now}
+	end
+
+	-- Conforming: advance TAT, key disappears once the client is idle again
+	redis.call('SET', key, new_tat, 'PX', math.ceil((new_tat - now) / 1000))
+
+	-- Full burst is available again once TAT is reached
+	local remaining = math.floor((tolerance - (new_tat - now)) / emission_interval)
+	return {1, remaining, 0, new_tat - now}
+`)
+
+// durationUntil converts a rate wait (units / units per second) to a duration
+func durationUntil(units float64, rate float64) time.Duration {
+	if units <= 0 || rate <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(units / rate * float64(time.Second)))
+}
+
 type AtomicLimiter struct {
-	client     *redis.Client
+	client     redis.UniversalClient
 	limit      int
 	windowSize time.Duration
+	period     string         // calendar period, overrides windowSize when set
+	location   *time.Location // timezone the calendar period is aligned to
 }
-func NewAtomicFixedWindow(client *redis.Client, limit int, windowSize time.Duration) *AtomicLimiter {
+func NewAtomicFixedWindow(client redis.UniversalClient, limit int, windowSize time.Duration) *AtomicLimiter {
 	return &AtomicLimiter{
 		client: client, 
 		limit: limit, 
@@ -96,12 +234,23 @@ func NewAtomicFixedWindow(client *redis.Client, limit int, windowSize time.Durat
 }
 
 type AtomicSlidingWindowLimiter struct {
-	client     *redis.Client
+	client     redis.UniversalClient
 	limit      int
 	windowSize time.Duration
 }
 
-func NewAtomicSlidingWindow(client *redis.Client, limit int, windowSize time.Duration) *AtomicSlidingWindowLimiter {
+// NewAtomicCalendarWindow is a fixed window that resets on calendar boundaries
+// (local midnight, Monday, the 1st of the month) in loc.
+func NewAtomicCalendarWindow(client redis.UniversalClient, limit int, period string, loc *time.Location) *AtomicLimiter {
+	return &AtomicLimiter{
+		client:   client,
+		limit:    limit,
+		period:   period,
+		location: loc,
+	}
+}
+
+func NewAtomicSlidingWindow(client redis.UniversalClient, limit int, windowSize time.Duration) *AtomicSlidingWindowLimiter {
 	return &AtomicSlidingWindowLimiter{
 		client: client, 
 		limit: limit, 
@@ -109,53 +258,417 @@ func NewAtomicSlidingWindow(client *redis.Client, limit int, windowSize time.Dur
 	}
 }
 
-func (a *AtomicLimiter) Allow(ctx context.Context, clientID string) (bool, int, error) {
-	windowStart := time.Now().Truncate(a.windowSize).Unix()
-	key := fmt.Sprintf("rate:atomic:fixed:%s:%d", clientID, windowStart)
+type AtomicSlidingWindowCounterLimiter struct {
+	client     redis.UniversalClient
+	limit      int
+	windowSize time.Duration
+}
+
+func NewAtomicSlidingWindowCounter(client redis.UniversalClient, limit int, windowSize time.Duration) *AtomicSlidingWindowCounterLimiter {
+	return &AtomicSlidingWindowCounterLimiter{
+		client:     client,
+		limit:      limit,
+		windowSize: windowSize,
+	}
+}
+
+type AtomicTokenBucketLimiter struct {
+	client     redis.UniversalClient
+	capacity   int     // max tokens the bucket can hold
+	refillRate float64 // tokens added per second
+	ttl        time.Duration
+}
+
+func NewAtomicTokenBucket(client redis.UniversalClient, capacity int, refillRate float64) *AtomicTokenBucketLimiter {
+	// Keep state around for as long as it takes an empty bucket to refill,
+	// after that a missing key and a full bucket are the same thing
+	ttl := time.Second
+	if refillRate > 0 {
+		ttl = time.Duration(math.Ceil(float64(capacity)/refillRate)+1) * time.Second
+	}
+
+	return &AtomicTokenBucketLimiter{
+		client:     client,
+		capacity:   capacity,
+		refillRate: refillRate,
+		ttl:        ttl,
+	}
+}
+
+type AtomicLeakyBucketLimiter struct {
+	client   redis.UniversalClient
+	capacity int     // burst allowed on top of the drain rate, 1 for none
+	leakRate float64 // requests drained per second
+	ttl      time.Duration
+}
+
+func NewAtomicLeakyBucket(client redis.UniversalClient, capacity int, leakRate float64) *AtomicLeakyBucketLimiter {
+	// A full bucket is empty again after capacity / leakRate seconds
+	ttl := time.Second
+	if leakRate > 0 {
+		ttl = time.Duration(math.Ceil(float64(capacity)/leakRate)+1) * time.Second
+	}
+
+	return &AtomicLeakyBucketLimiter{
+		client:   client,
+		capacity: capacity,
+		leakRate: leakRate,
+		ttl:      ttl,
+	}
+}
+
+type AtomicGCRALimiter struct {
+	client           redis.UniversalClient
+	emissionInterval time.Duration // time between requests at the steady rate
+	burst            int           // requests allowed back to back from idle
+}
+
+func NewAtomicGCRA(client redis.UniversalClient, burst int, rate float64) *AtomicGCRALimiter {
+	emissionInterval := time.Second
+	if rate > 0 {
+		emissionInterval = time.Duration(float64(time.Second) / rate)
+	}
+
+	return &AtomicGCRALimiter{
+		client:           client,
+		emissionInterval: emissionInterval,
+		burst:            burst,
+	}
+}
+
+// Window returns the start and end of the window containing now
+func (a *AtomicLimiter) Window(now time.Time) (time.Time, time.Time) {
+	if a.period != "" {
+		return limiter.CalendarWindow(a.period, a.location, now)
+	}
+	start := now.Truncate(a.windowSize)
+	return start, start.Add(a.windowSize)
+}
+
+func (a *AtomicLimiter) key(clientID string, now time.Time) string {
+	windowStart, _ := a.Window(now)
+	return fmt.Sprintf("rate:atomic:fixed:%s:%d", hashTag(clientID), windowStart.Unix())
+}
+
+// ttl is how long the current window's counter has to live, in whole seconds
+func (a *AtomicLimiter) ttl(now time.Time) int {
+	_, windowEnd := a.Window(now)
+	return int(math.Ceil(windowEnd.Sub(now).Seconds()))
+}
+
+func (a *AtomicSlidingWindowLimiter) key(clientID string) string {
+	return fmt.Sprintf("rate:atomic:sliding:%s", hashTag(clientID))
+}
+
+func (a *AtomicSlidingWindowCounterLimiter) keys(clientID string, now time.Time) (string, string) {
+	currentStart := now.Truncate(a.windowSize)
+	previousStart := currentStart.Add(-a.windowSize)
+	return fmt.Sprintf("rate:atomic:swc:%s:%d", hashTag(clientID), currentStart.Unix()),
+		fmt.Sprintf("rate:atomic:swc:%s:%d", hashTag(clientID), previousStart.Unix())
+}
+
+func (a *AtomicTokenBucketLimiter) keys(clientID string) (string, string) {
+	return fmt.Sprintf("rate:atomic:bucket:%s:tokens", hashTag(clientID)),
+		fmt.Sprintf("rate:atomic:bucket:%s:last_refill", hashTag(clientID))
+}
+
+func (a *AtomicLeakyBucketLimiter) key(clientID string) string {
+	return fmt.Sprintf("rate:atomic:leaky:%s", hashTag(clientID))
+}
+
+func (a *AtomicGCRALimiter) key(clientID string) string {
+	return fmt.Sprintf("rate:atomic:gcra:%s", hashTag(clientID))
+}
+
+func (a *AtomicLimiter) Allow(ctx context.Context, clientID string, cost int) (limiter.Result, error) {
+	return a.queue(ctx, a.client, clientID, cost)()
+}
+
+func (a *AtomicLimiter) queue(ctx context.Context, c redis.Scripter, clientID string, cost int) func() (limiter.Result, error) {
+	now := time.Now()
+	key := a.key(clientID, now)
 
-	result, err := fixedWindowScript.Run(
+	cmd := fixedWindowScript.Run(
 		ctx,
-		a.client,
+		c,
 		[]string{key},
 		a.limit,
-		int(a.windowSize.Seconds()),
-	).Int()
+		a.ttl(now),
+		cost,
+	)
 
-	if err != nil {
-		return false, 0, fmt.Errorf("lua script error: %w", err)
-	}
+	return func() (limiter.Result, error) {
+		result, err := cmd.Int64Slice()
+		if err != nil {
+			return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+		}
+
+		remaining := a.limit - int(result[1])
+		if remaining < 0 {
+			remaining = 0
+		}
 
-	remaining := a.limit - result
-	if remaining < 0 {
-		remaining = 0
+		// Everything comes back when the window rolls over
+		_, windowEnd := a.Window(now)
+		res := limiter.Result{Allowed: result[0] == 1, Remaining: remaining, ResetAt: windowEnd}
+		if !res.Allowed {
+			res.RetryAfter = windowEnd.Sub(now)
+		}
+
+		return res, nil
 	}
+}
 
-	return result <= a.limit, remaining, nil
+func (a *AtomicSlidingWindowLimiter) Allow(ctx context.Context, clientID string, cost int) (limiter.Result, error) {
+	return a.queue(ctx, a.client, clientID, cost)()
 }
 
-func (a *AtomicSlidingWindowLimiter) Allow(ctx context.Context, clientID string) (bool, int, error) {
-	key := fmt.Sprintf("rate:atomic:sliding:%s", clientID)
-	now := time.Now().UnixMicro()
-	windowStart := time.Now().Add(-a.windowSize).UnixMicro()
+func (a *AtomicSlidingWindowLimiter) queue(ctx context.Context, c redis.Scripter, clientID string, cost int) func() (limiter.Result, error) {
+	key := a.key(clientID)
+	now := time.Now()
 
-	result, err := slidingWindowScript.Run(
+	cmd := slidingWindowScript.Run(
 		ctx,
-		a.client,
+		c,
 		[]string{key},
-		now,
-		windowStart,
+		now.UnixMicro(),
+		now.Add(-a.windowSize).UnixMicro(),
 		a.limit,
 		int(a.windowSize.Seconds()),
-	).Int()
+		cost,
+	)
+
+	return func() (limiter.Result, error) {
+		result, err := cmd.Int64Slice()
+		if err != nil {
+			return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+		}
+
+		remaining := a.limit - int(result[1])
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		res := limiter.Result{Allowed: result[0] == 1, Remaining: remaining, ResetAt: now}
 
-	if err != nil {
-		return false, 0, fmt.Errorf("lua script error: %w", err)
+		// Entries leave the window one windowSize after they were added
+		if newestAt := result[3]; newestAt > 0 {
+			res.ResetAt = time.UnixMicro(newestAt).Add(a.windowSize)
+		}
+		if !res.Allowed {
+			res.RetryAfter = a.windowSize // cost larger than the limit never fits
+			if freedAt := result[2]; freedAt > 0 {
+				res.RetryAfter = time.UnixMicro(freedAt).Add(a.windowSize).Sub(now)
+			}
+		}
+
+		return res, nil
 	}
+}
+
+// Allow weights the previous fixed window's count by how much of it still overlaps
+// the sliding window. Two counters per client instead of one ZSET entry per request.
+func (a *AtomicSlidingWindowCounterLimiter) Allow(ctx context.Context, clientID string, cost int) (limiter.Result, error) {
+	return a.queue(ctx, a.client, clientID, cost)()
+}
+
+func (a *AtomicSlidingWindowCounterLimiter) queue(ctx context.Context, c redis.Scripter, clientID string, cost int) func() (limiter.Result, error) {
+	now := time.Now()
+	currentKey, previousKey := a.keys(clientID, now)
+	elapsed := now.Sub(now.Truncate(a.windowSize))
+	previousWeight := 1 - float64(elapsed)/float64(a.windowSize)
+
+	cmd := slidingWindowCounterScript.Run(
+		ctx,
+		c,
+		[]string{currentKey, previousKey},
+		a.limit,
+		int(a.windowSize.Seconds()),
+		previousWeight,
+		cost,
+	)
+
+	return func() (limiter.Result, error) {
+		result, err := cmd.Int64Slice()
+		if err != nil {
+			return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+		}
+
+		previous, current := float64(result[1]), float64(result[2])
+		estimated := previous*previousWeight + current
+
+		remaining := int(math.Floor(float64(a.limit) - estimated))
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		res := limiter.Result{Allowed: result[0] == 1, Remaining: remaining, ResetAt: now}
+
+		// The current bucket stops counting two windows after it started,
+		// the previous one at the end of the current window
+		untilWindowEnd := a.windowSize - elapsed
+		switch {
+		case current > 0:
+			res.ResetAt = now.Add(untilWindowEnd + a.windowSize)
+		case previous > 0:
+			res.ResetAt = now.Add(untilWindowEnd)
+		}
+
+		if !res.Allowed {
+			res.RetryAfter = a.retryAfter(previous, current, float64(cost), elapsed)
+		}
 
-	remaining := a.limit - result
-	if remaining < 0 {
-		remaining = 0
+		return res, nil
 	}
+}
+
+// retryAfter solves for the first moment previous*weight + current + cost fits
+// under the limit, as the previous bucket's weight decays through the window.
+func (a *AtomicSlidingWindowCounterLimiter) retryAfter(previous, current, cost float64, elapsed time.Duration) time.Duration {
+	limit := float64(a.limit)
+	window := float64(a.windowSize)
+
+	// Still within the current window, if the current bucket leaves room
+	if budget := limit - cost - current; budget >= 0 && previous > 0 {
+		fraction := 1 - budget/previous
+		return time.Duration(math.Ceil(fraction*window)) - elapsed
+	}
+
+	// Otherwise current becomes previous and has to decay in the next window
+	untilWindowEnd := a.windowSize - elapsed
+	if current <= 0 || limit-cost < 0 {
+		return untilWindowEnd
+	}
+	fraction := math.Max(0, 1-(limit-cost)/current)
+	return untilWindowEnd + time.Duration(math.Ceil(fraction*window))
+}
+
+func (a *AtomicTokenBucketLimiter) Allow(ctx context.Context, clientID string, cost int) (limiter.Result, error) {
+	return a.queue(ctx, a.client, clientID, cost)()
+}
+
+func (a *AtomicTokenBucketLimiter) queue(ctx context.Context, c redis.Scripter, clientID string, cost int) func() (limiter.Result, error) {
+	tokensKey, lastRefillKey := a.keys(clientID)
+	now := time.Now()
+
+	cmd := tokenBucketScript.Run(
+		ctx,
+		c,
+		[]string{tokensKey, lastRefillKey},
+		a.capacity,
+		a.refillRate,
+		now.UnixMicro(),
+		int(a.ttl.Seconds()),
+		cost,
+	)
+
+	return func() (limiter.Result, error) {
+		result, err := cmd.Slice()
+		if err != nil {
+			return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+		}
+
+		allowed, _ := result[0].(int64)
+		tokensStr, _ := result[1].(string)
+		tokens, err := strconv.ParseFloat(tokensStr, 64)
+		if err != nil {
+			return limiter.Result{}, fmt.Errorf("invalid token count %q: %w", tokensStr, err)
+		}
+
+		// Only whole tokens can be spent, so round down
+		res := limiter.Result{
+			Allowed:   allowed == 1,
+			Remaining: int(math.Floor(tokens)),
+			ResetAt:   now.Add(durationUntil(float64(a.capacity)-tokens, a.refillRate)),
+		}
+		if !res.Allowed {
+			res.RetryAfter = durationUntil(float64(cost)-tokens, a.refillRate)
+		}
+
+		return res, nil
+	}
+}
+
+// Allow reports how long the caller has to wait for the next slot to drain
+// when it is blocked, so traffic can be paced to the drain rate.
+func (a *AtomicLeakyBucketLimiter) Allow(ctx context.Context, clientID string, cost int) (limiter.Result, error) {
+	return a.queue(ctx, a.client, clientID, cost)()
+}
 
-	return result <= a.limit, remaining, nil
-}
\ No newline at end of file
+func (a *AtomicLeakyBucketLimiter) queue(ctx context.Context, c redis.Scripter, clientID string, cost int) func() (limiter.Result, error) {
+	key := a.key(clientID)
+	now := time.Now()
+
+	cmd := leakyBucketScript.Run(
+		ctx,
+		c,
+		[]string{key},
+		a.capacity,
+		a.leakRate,
+		now.UnixMicro(),
+		int(a.ttl.Seconds()),
+		cost,
+	)
+
+	return func() (limiter.Result, error) {
+		result, err := cmd.Slice()
+		if err != nil {
+			return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+		}
+
+		allowed, _ := result[0].(int64)
+		levelStr, _ := result[1].(string)
+		waitMicros, _ := result[2].(int64)
+		level, err := strconv.ParseFloat(levelStr, 64)
+		if err != nil {
+			return limiter.Result{}, fmt.Errorf("invalid bucket level %q: %w", levelStr, err)
+		}
+
+		remaining := int(math.Floor(float64(a.capacity) - level))
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		return limiter.Result{
+			Allowed:    allowed == 1,
+			Remaining:  remaining,
+			RetryAfter: time.Duration(waitMicros) * time.Microsecond,
+			ResetAt:    now.Add(durationUntil(level, a.leakRate)),
+		}, nil
+	}
+}
+
+// Allow keeps a single theoretical arrival time per client, so memory use does
+// not grow with the limit the way the sliding window ZSET does.
+func (a *AtomicGCRALimiter) Allow(ctx context.Context, clientID string, cost int) (limiter.Result, error) {
+	return a.queue(ctx, a.client, clientID, cost)()
+}
+
+func (a *AtomicGCRALimiter) queue(ctx context.Context, c redis.Scripter, clientID string, cost int) func() (limiter.Result, error) {
+	key := a.key(clientID)
+	now := time.Now()
+
+	cmd := gcraScript.Run(
+		ctx,
+		c,
+		[]string{key},
+		a.emissionInterval.Microseconds(),
+		a.burst,
+		now.UnixMicro(),
+		cost,
+	)
+
+	return func() (limiter.Result, error) {
+		result, err := cmd.Int64Slice()
+		if err != nil {
+			return limiter.Result{}, fmt.Errorf("lua script error: %w", err)
+		}
+
+		return limiter.Result{
+			Allowed:    result[0] == 1,
+			Remaining:  int(result[1]),
+			RetryAfter: time.Duration(result[2]) * time.Microsecond,
+			ResetAt:    now.Add(time.Duration(result[3]) * time.Microsecond),
+		}, nil
+	}
+}
@@ -0,0 +1,334 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestSlidingWindowCounterRetryAfter(t *testing.T) {
+	a := NewAtomicSlidingWindowCounter(nil, 10, time.Minute)
+
+	tests := []struct {
+		name              string
+		previous, current float64
+		cost              float64
+		elapsed           time.Duration
+		want              time.Duration
+	}{
+		{
+			name:     "previous window decays enough within this one",
+			previous: 10, cost: 1,
+			want: 6 * time.Second,
+		},
+		{
+			name:     "part way through with some counted already",
+			previous: 10, current: 5, cost: 1, elapsed: 30 * time.Second,
+			want: 6 * time.Second,
+		},
+		{
+			name:     "cost above one",
+			previous: 10, current: 2, cost: 4, elapsed: 12 * time.Second,
+			want: 24 * time.Second,
+		},
+		{
+			name:    "current window full, it has to decay in the next one",
+			current: 10, cost: 1, elapsed: 15 * time.Second,
+			want: 51 * time.Second,
+		},
+		{
+			name:     "previous window drops out before the current one fits",
+			previous: 4, current: 9, cost: 2, elapsed: 45 * time.Second,
+			want: 15*time.Second + 6666666667*time.Nanosecond, // 9 decaying to 8, a ninth of the way
+		},
+		{
+			name: "cost above the limit waits for the window to end",
+			cost: 11, elapsed: 20 * time.Second,
+			want: 40 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := a.retryAfter(tt.previous, tt.current, tt.cost, tt.elapsed)
+			if got != tt.want {
+				t.Fatalf("retryAfter = %v, want %v", got, tt.want)
+			}
+			if tt.cost > float64(a.limit) {
+				return
+			}
+
+			// The estimate CheckLimit compares against the limit has to fit at
+			// the returned time, and not a moment before
+			if est := estimateAt(a, tt.previous, tt.current, tt.elapsed+got); est+tt.cost > float64(a.limit)+1e-9 {
+				t.Errorf("estimate %v + cost %v still over the limit after retryAfter", est, tt.cost)
+			}
+			if est := estimateAt(a, tt.previous, tt.current, tt.elapsed+got-time.Millisecond); est+tt.cost <= float64(a.limit) {
+				t.Errorf("estimate %v + cost %v already fit a millisecond earlier", est, tt.cost)
+			}
+		})
+	}
+}
+
+// estimateAt is the sliding window estimate at `at` into the window the counts
+// were read in, assuming nothing else is counted meanwhile
+func estimateAt(a *AtomicSlidingWindowCounterLimiter, previous, current float64, at time.Duration) float64 {
+	window := float64(a.windowSize)
+	if at < a.windowSize {
+		return previous*(1-float64(at)/window) + current
+	}
+	// current has become the previous window
+	return current * (1 - float64(at-a.windowSize)/window)
+}
+
+func TestSlidingWindowSameMicrosecond(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// Run the script directly so every call lands on the same microsecond
+	now := time.Date(2026, 1, 1, 0, 0, 0, 123456000, time.UTC)
+	check := func(cost int) bool {
+		t.Helper()
+		result, err := slidingWindowScript.Run(ctx, client, []string{"sliding"},
+			now.UnixMicro(), now.Add(-time.Minute).UnixMicro(), 7, 60, cost).Int64Slice()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result[0] == 1
+	}
+
+	for i, tt := range []struct {
+		cost    int
+		allowed bool
+	}{
+		{cost: 2, allowed: true},
+		{cost: 3, allowed: true},
+		{cost: 2, allowed: true},
+		{cost: 1, allowed: false},
+	} {
+		if got := check(tt.cost); got != tt.allowed {
+			t.Fatalf("check %d (cost %d): allowed = %v, want %v", i, tt.cost, got, tt.allowed)
+		}
+	}
+
+	members, err := client.ZRange(ctx, "sliding", 0, -1).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(members) != 7 {
+		t.Fatalf("%d entries counted, want 7: %v", len(members), members)
+	}
+	// The exact timestamp, not a rounded float
+	if want := strconv.FormatInt(now.UnixMicro(), 10) + ":"; !strings.HasPrefix(members[0], want) {
+		t.Errorf("member %q doesn't start with %q", members[0], want)
+	}
+}
+
+func TestTokenBucketExactRefillTime(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// Not a multiple of 100µs. Redis' tostring formats with %.14g and would
+	// round it, miniredis doesn't, so this pins the stored format rather than
+	// reproducing the rounding.
+	now := time.Date(2026, 1, 1, 0, 0, 0, 123457000, time.UTC).UnixMicro()
+	want := strconv.FormatInt(now, 10)
+
+	if err := tokenBucketScript.Run(ctx, client, []string{"tokens", "last_refill"}, 10, 1000.0, now, 60, 1).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustGet(t, mr, "last_refill"); got != want {
+		t.Errorf("check stored last_refill %q, want %q", got, want)
+	}
+
+	if _, err := runRefund(ctx, client, tokenBucketRefundScript, []string{"tokens", "last_refill"}, 10, 1000.0, now+1, 60, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mustGet(t, mr, "last_refill"), strconv.FormatInt(now+1, 10); got != want {
+		t.Errorf("refund stored last_refill %q, want %q", got, want)
+	}
+}
+
+func mustGet(t *testing.T, mr *miniredis.Miniredis, key string) string {
+	t.Helper()
+	v, err := mr.Get(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLeakyBucketExactLeakTime(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// See TestTokenBucketExactRefillTime
+	now := time.Date(2026, 1, 1, 0, 0, 0, 123457000, time.UTC).UnixMicro()
+
+	if err := leakyBucketScript.Run(ctx, client, []string{"leaky"}, 10, 1000.0, now, 60, 1).Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mr.HGet("leaky", "last_leak"), strconv.FormatInt(now, 10); got != want {
+		t.Errorf("check stored last_leak %q, want %q", got, want)
+	}
+
+	if _, err := runRefund(ctx, client, leakyBucketRefundScript, []string{"leaky"}, 1000.0, now+1, 60, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := mr.HGet("leaky", "last_leak"), strconv.FormatInt(now+1, 10); got != want {
+		t.Errorf("refund stored last_leak %q, want %q", got, want)
+	}
+}
+
+func TestGCRATiming(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// One request every 100ms with a burst of 3. In order, against the same key.
+	const interval = 100000
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro()
+	tests := []struct {
+		name      string
+		at        int64 // µs since start
+		cost      int
+		allowed   bool
+		remaining int64
+		retryUs   int64
+		resetUs   int64
+	}{
+		{name: "first of the burst", at: 0, cost: 1, allowed: true, remaining: 2, resetUs: 100000},
+		{name: "second of the burst", at: 0, cost: 1, allowed: true, remaining: 1, resetUs: 200000},
+		{name: "burst used up", at: 0, cost: 1, allowed: true, remaining: 0, resetUs: 300000},
+		{name: "one interval early", at: 0, cost: 1, retryUs: 100000, resetUs: 300000},
+		{name: "exactly on time", at: 100000, cost: 1, allowed: true, remaining: 0, resetUs: 300000},
+		{name: "cost waits for all its intervals", at: 150000, cost: 2, retryUs: 150000, resetUs: 250000},
+		{name: "caught up, whole burst at once", at: 400000, cost: 3, allowed: true, remaining: 0, resetUs: 300000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := gcraScript.Run(ctx, client, []string{"gcra"}, interval, 3, start+tt.at, tt.cost).Int64Slice()
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []int64{0, tt.remaining, tt.retryUs, tt.resetUs}
+			if tt.allowed {
+				want[0] = 1
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("got {allowed, remaining, retry_us, reset_us} = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestLeakyBucketTiming(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// Holds 2, drains 10 a second. In order, against the same key.
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC).UnixMicro()
+	tests := []struct {
+		name    string
+		at      int64 // µs since start
+		cost    int
+		allowed bool
+		level   string
+		waitUs  int64
+	}{
+		{name: "empty bucket", at: 0, cost: 1, allowed: true, level: "1"},
+		{name: "fills up", at: 0, cost: 1, allowed: true, level: "2"},
+		{name: "full, waits one drain", at: 0, cost: 1, level: "2", waitUs: 100000},
+		{name: "half drained, waits the rest", at: 50000, cost: 1, level: "1.5", waitUs: 50000},
+		{name: "exactly on time", at: 100000, cost: 1, allowed: true, level: "2"},
+		{name: "empty again takes more than capacity", at: 1000000, cost: 5, allowed: true, level: "5"},
+		{name: "overfull waits to drain below capacity", at: 1000000, cost: 1, level: "5", waitUs: 400000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := leakyBucketScript.Run(ctx, client, []string{"leaky"}, 2, 10.0, start+tt.at, 60, tt.cost).Slice()
+			if err != nil {
+				t.Fatal(err)
+			}
+			allowed, _ := got[0].(int64)
+			level, _ := got[1].(string)
+			waitUs, _ := got[2].(int64)
+			if (allowed == 1) != tt.allowed || level != tt.level || waitUs != tt.waitUs {
+				t.Fatalf("got {allowed, level, wait_us} = %v, want {%v, %s, %d}", got, tt.allowed, tt.level, tt.waitUs)
+			}
+		})
+	}
+}
+
+func TestConcurrencyScripts(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	ctx := context.Background()
+
+	// Two slots, leases last 10s. In order, against the same key.
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mr.SetTime(start)
+	const ttl = 10 * time.Second
+	tests := []struct {
+		name    string
+		release bool // release the lease instead of acquiring it
+		lease   string
+		at      time.Duration
+		expired bool    // the key has expired by now, longest lease and all
+		want    []int64 // {acquired, remaining, retry_us}, or {released}
+	}{
+		{name: "first slot", lease: "a", want: []int64{1, 1, 0}},
+		{name: "second slot", lease: "b", at: time.Second, want: []int64{1, 0, 0}},
+		{name: "full until the oldest lease expires", lease: "c", at: 2 * time.Second, want: []int64{0, 0, 8000000}},
+		{name: "release frees a slot", release: true, lease: "a", at: 3 * time.Second, want: []int64{1}},
+		{name: "release is only once", release: true, lease: "a", at: 3 * time.Second, want: []int64{0}},
+		{name: "freed slot", lease: "c", at: 3 * time.Second, want: []int64{1, 0, 0}},
+		{name: "release at expiry gives nothing back", release: true, lease: "b", at: 11 * time.Second, want: []int64{0}},
+		{name: "lapsed lease no longer holds its slot", lease: "d", at: 12 * time.Second, want: []int64{1, 0, 0}},
+		{name: "release after expiry gives nothing back", release: true, lease: "c", at: 14 * time.Second, want: []int64{0}},
+		{name: "release after the key expired", release: true, lease: "d", at: time.Minute, expired: true, want: []int64{0}},
+	}
+
+	var elapsed time.Duration
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Redis expires the key on its own clock
+			mr.SetTime(start.Add(tt.at))
+			mr.FastForward(tt.at - elapsed)
+			elapsed = tt.at
+			if tt.expired && mr.Exists("inflight") {
+				t.Fatal("key outlived its longest lease")
+			}
+
+			now := start.Add(tt.at).UnixMicro()
+			var got []int64
+			var err error
+			if tt.release {
+				var released int64
+				released, err = releaseConcurrencyScript.Run(ctx, client, []string{"inflight"}, tt.lease, now).Int64()
+				got = []int64{released}
+			} else {
+				got, err = acquireConcurrencyScript.Run(ctx, client, []string{"inflight"},
+					2, now, start.Add(tt.at+ttl).UnixMicro(), tt.lease).Int64Slice()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}